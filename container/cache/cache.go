@@ -0,0 +1,351 @@
+// Package cache provides generic in-process caches with LRU, LFU and TTL eviction.
+//
+// The results of remote calls such as rpc.APIClient can be memoized with GetOrLoad:
+//
+//	users := cache.NewLRU[string, *User](cache.Options[string, *User]{MaxEntries: 1000, TTL: time.Minute})
+//	user, err := users.GetOrLoad(ctx, userID, func(ctx context.Context) (*User, error) {
+//		return fetchUser(ctx, client, userID)
+//	})
+package cache
+
+import (
+	"container/list"
+	"sync"
+	"time"
+)
+
+// EvictReason tells why an entry leaves the cache
+type EvictReason int
+
+const (
+	// EvictCapacity means the entry is evicted to fit the max entries or max cost
+	EvictCapacity EvictReason = iota
+	// EvictExpired means the entry reaches its ttl
+	EvictExpired
+	// EvictDeleted means the entry is deleted or cleared by the caller
+	EvictDeleted
+)
+
+// String returns the name of the reason
+func (r EvictReason) String() string {
+	switch r {
+	case EvictCapacity:
+		return "capacity"
+	case EvictExpired:
+		return "expired"
+	case EvictDeleted:
+		return "deleted"
+	default:
+		return "unknown"
+	}
+}
+
+// Options is the options of the cache
+type Options[K comparable, V any] struct {
+	MaxEntries int                                      // max number of entries, 0 means unlimited
+	MaxCost    int64                                    // max total cost of entries, 0 means unlimited
+	Cost       func(key K, value V) int64               // cost of the entry set by Set, default 1
+	TTL        time.Duration                            // default ttl of the entries, 0 means never expire
+	OnEvict    func(key K, value V, reason EvictReason) // called without holding the lock
+	Now        func() time.Time                         // default time.Now
+}
+
+// Stats is the statistics of the cache
+type Stats struct {
+	Hits       uint64
+	Misses     uint64
+	Evictions  uint64 // evicted by capacity or expiry
+	Loads      uint64 // successful loads by GetOrLoad
+	LoadErrors uint64
+}
+
+// HitRate returns the ratio of hits in all lookups
+func (s Stats) HitRate() float64 {
+	total := s.Hits + s.Misses
+	if total == 0 {
+		return 0
+	}
+	return float64(s.Hits) / float64(total)
+}
+
+type entry[K comparable, V any] struct {
+	key      K
+	value    V
+	cost     int64
+	expireAt time.Time
+
+	elem  *list.Element // lru
+	freq  uint64        // lfu
+	tick  uint64        // lfu
+	index int           // lfu heap index
+}
+
+func (e *entry[K, V]) expired(now time.Time) bool {
+	return !e.expireAt.IsZero() && !now.Before(e.expireAt)
+}
+
+type evicted[K comparable, V any] struct {
+	key    K
+	value  V
+	reason EvictReason
+}
+
+// Cache is a generic in-process cache, it is routine-safe
+type Cache[K comparable, V any] struct {
+	mu     sync.Mutex
+	opts   Options[K, V]
+	items  map[K]*entry[K, V]
+	policy policy[K, V]
+	cost   int64
+	stats  Stats
+	calls  map[K]*call[V]
+}
+
+// NewLRU creates a cache evicting the least recently used entries
+func NewLRU[K comparable, V any](opts Options[K, V]) *Cache[K, V] {
+	return newCache(opts, newLRUPolicy[K, V]())
+}
+
+// NewLFU creates a cache evicting the least frequently used entries
+func NewLFU[K comparable, V any](opts Options[K, V]) *Cache[K, V] {
+	return newCache(opts, newLFUPolicy[K, V]())
+}
+
+// NewTTL creates a cache whose entries expire after ttl, the least recently used
+// entries are evicted first when the capacity in opts is reached.
+func NewTTL[K comparable, V any](ttl time.Duration, opts Options[K, V]) *Cache[K, V] {
+	opts.TTL = ttl
+	return newCache(opts, newLRUPolicy[K, V]())
+}
+
+func newCache[K comparable, V any](opts Options[K, V], p policy[K, V]) *Cache[K, V] {
+	if opts.Now == nil {
+		opts.Now = time.Now
+	}
+	return &Cache[K, V]{
+		opts:   opts,
+		items:  make(map[K]*entry[K, V]),
+		policy: p,
+		calls:  make(map[K]*call[V]),
+	}
+}
+
+func (c *Cache[K, V]) notify(evictions []evicted[K, V]) {
+	if c.opts.OnEvict == nil {
+		return
+	}
+	for _, e := range evictions {
+		c.opts.OnEvict(e.key, e.value, e.reason)
+	}
+}
+
+// removeLocked removes the entry, the lock must be held
+func (c *Cache[K, V]) removeLocked(e *entry[K, V], reason EvictReason, evictions []evicted[K, V]) []evicted[K, V] {
+	c.policy.remove(e)
+	delete(c.items, e.key)
+	c.cost -= e.cost
+	if reason != EvictDeleted {
+		c.stats.Evictions++
+	}
+	return append(evictions, evicted[K, V]{key: e.key, value: e.value, reason: reason})
+}
+
+// Get returns the value of the key, the expired entry is treated as missing
+func (c *Cache[K, V]) Get(key K) (value V, ok bool) {
+	var evictions []evicted[K, V]
+	c.mu.Lock()
+	value, ok, evictions = c.getLocked(key, evictions)
+	c.mu.Unlock()
+	c.notify(evictions)
+	return
+}
+
+func (c *Cache[K, V]) getLocked(key K, evictions []evicted[K, V]) (value V, ok bool, _ []evicted[K, V]) {
+	e, exists := c.items[key]
+	if exists && e.expired(c.opts.Now()) {
+		evictions = c.removeLocked(e, EvictExpired, evictions)
+		exists = false
+	}
+	if !exists {
+		c.stats.Misses++
+		return value, false, evictions
+	}
+	c.stats.Hits++
+	c.policy.access(e)
+	return e.value, true, evictions
+}
+
+// Peek returns the value of the key without updating the stats and the eviction order
+func (c *Cache[K, V]) Peek(key K) (value V, ok bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	e, exists := c.items[key]
+	if !exists || e.expired(c.opts.Now()) {
+		return
+	}
+	return e.value, true
+}
+
+// Set sets the value with the default ttl and the cost by Options.Cost
+func (c *Cache[K, V]) Set(key K, value V) {
+	c.SetWithCost(key, value, c.costOf(key, value))
+}
+
+// SetWithCost sets the value with the default ttl and the specified cost
+func (c *Cache[K, V]) SetWithCost(key K, value V, cost int64) {
+	c.set(key, value, cost, c.opts.TTL)
+}
+
+// SetWithTTL sets the value with the specified ttl, 0 means never expire
+func (c *Cache[K, V]) SetWithTTL(key K, value V, ttl time.Duration) {
+	c.set(key, value, c.costOf(key, value), ttl)
+}
+
+func (c *Cache[K, V]) costOf(key K, value V) int64 {
+	if c.opts.Cost != nil {
+		return c.opts.Cost(key, value)
+	}
+	return 1
+}
+
+func (c *Cache[K, V]) set(key K, value V, cost int64, ttl time.Duration) {
+	var evictions []evicted[K, V]
+	c.mu.Lock()
+	c.invalidateLoadLocked(key)
+	evictions = c.setLocked(key, value, cost, ttl, evictions)
+	c.mu.Unlock()
+	c.notify(evictions)
+}
+
+func (c *Cache[K, V]) setLocked(key K, value V, cost int64, ttl time.Duration, evictions []evicted[K, V]) []evicted[K, V] {
+	e, exists := c.items[key]
+	if exists {
+		// detach the old entry so it is never chosen as the victim
+		c.policy.remove(e)
+		delete(c.items, key)
+		c.cost -= e.cost
+	}
+	if c.opts.MaxCost > 0 && cost > c.opts.MaxCost {
+		// the entry never fits, drop it together with the old value
+		if exists {
+			c.stats.Evictions++
+			evictions = append(evictions, evicted[K, V]{key: key, value: e.value, reason: EvictCapacity})
+		}
+		c.stats.Evictions++
+		return append(evictions, evicted[K, V]{key: key, value: value, reason: EvictCapacity})
+	}
+	if !exists {
+		e = &entry[K, V]{key: key}
+	}
+	e.value, e.cost, e.expireAt = value, cost, time.Time{}
+	if ttl > 0 {
+		e.expireAt = c.opts.Now().Add(ttl)
+	}
+
+	now := c.opts.Now()
+	for c.fullLocked(cost) {
+		victim := c.policy.victim()
+		if victim == nil {
+			break
+		}
+		reason := EvictCapacity
+		if victim.expired(now) {
+			reason = EvictExpired
+		}
+		evictions = c.removeLocked(victim, reason, evictions)
+	}
+	c.items[key] = e
+	c.cost += cost
+	c.policy.add(e)
+	return evictions
+}
+
+// fullLocked returns true when there is no room for a new entry of the cost
+func (c *Cache[K, V]) fullLocked(cost int64) bool {
+	return (c.opts.MaxEntries > 0 && len(c.items) >= c.opts.MaxEntries) ||
+		(c.opts.MaxCost > 0 && c.cost+cost > c.opts.MaxCost)
+}
+
+// Delete removes the key and returns true when it was in the cache
+func (c *Cache[K, V]) Delete(key K) bool {
+	var evictions []evicted[K, V]
+	c.mu.Lock()
+	c.invalidateLoadLocked(key)
+	e, exists := c.items[key]
+	if exists {
+		evictions = c.removeLocked(e, EvictDeleted, evictions)
+	}
+	c.mu.Unlock()
+	c.notify(evictions)
+	return exists
+}
+
+// PurgeExpired removes all the expired entries and returns the number removed,
+// the expired entries are also removed lazily on access.
+func (c *Cache[K, V]) PurgeExpired() int {
+	var evictions []evicted[K, V]
+	c.mu.Lock()
+	now := c.opts.Now()
+	for _, e := range c.items {
+		if e.expired(now) {
+			evictions = c.removeLocked(e, EvictExpired, evictions)
+		}
+	}
+	c.mu.Unlock()
+	c.notify(evictions)
+	return len(evictions)
+}
+
+// Clear removes all the entries
+func (c *Cache[K, V]) Clear() {
+	var evictions []evicted[K, V]
+	c.mu.Lock()
+	if c.opts.OnEvict != nil {
+		for _, e := range c.items {
+			evictions = append(evictions, evicted[K, V]{key: e.key, value: e.value, reason: EvictDeleted})
+		}
+	}
+	for _, cl := range c.calls {
+		cl.stale = true
+	}
+	clear(c.items)
+	c.policy.clear()
+	c.cost = 0
+	c.mu.Unlock()
+	c.notify(evictions)
+}
+
+// Len returns the number of entries, including the expired ones not purged yet
+func (c *Cache[K, V]) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.items)
+}
+
+// Cost returns the total cost of the entries
+func (c *Cache[K, V]) Cost() int64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.cost
+}
+
+// Keys returns the keys of the entries not expired in random order
+func (c *Cache[K, V]) Keys() []K {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	now := c.opts.Now()
+	keys := make([]K, 0, len(c.items))
+	for k, e := range c.items {
+		if !e.expired(now) {
+			keys = append(keys, k)
+		}
+	}
+	return keys
+}
+
+// Stats returns a snapshot of the statistics
+func (c *Cache[K, V]) Stats() Stats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.stats
+}
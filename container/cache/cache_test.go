@@ -0,0 +1,289 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+type fakeClock struct {
+	now time.Time
+}
+
+func (c *fakeClock) Now() time.Time { return c.now }
+
+func (c *fakeClock) Advance(d time.Duration) { c.now = c.now.Add(d) }
+
+func TestLRUEviction(t *testing.T) {
+	var evictedKeys []string
+	c := NewLRU(Options[string, int]{
+		MaxEntries: 2,
+		OnEvict: func(key string, value int, reason EvictReason) {
+			if reason != EvictCapacity {
+				t.Fatalf("unexpected reason %s", reason)
+			}
+			evictedKeys = append(evictedKeys, key)
+		},
+	})
+	c.Set("a", 1)
+	c.Set("b", 2)
+	c.Get("a")
+	c.Set("c", 3)
+	if _, ok := c.Get("b"); ok {
+		t.Fatal("b should be evicted as the least recently used")
+	}
+	if v, ok := c.Get("a"); !ok || v != 1 {
+		t.Fatalf("a should be kept, got %d %v", v, ok)
+	}
+	if len(evictedKeys) != 1 || evictedKeys[0] != "b" {
+		t.Fatalf("unexpected evictions %v", evictedKeys)
+	}
+	stats := c.Stats()
+	if stats.Hits != 2 || stats.Misses != 1 || stats.Evictions != 1 {
+		t.Fatalf("unexpected stats %+v", stats)
+	}
+}
+
+func TestLFUEviction(t *testing.T) {
+	c := NewLFU(Options[string, int]{MaxEntries: 2})
+	c.Set("a", 1)
+	c.Set("b", 2)
+	c.Get("a")
+	c.Get("a")
+	c.Get("b")
+	c.Set("c", 3)
+	if _, ok := c.Peek("b"); ok {
+		t.Fatal("b should be evicted as the least frequently used")
+	}
+	// c has the lowest frequency now
+	c.Set("d", 4)
+	if _, ok := c.Peek("c"); ok {
+		t.Fatal("c should be evicted")
+	}
+	if _, ok := c.Peek("a"); !ok {
+		t.Fatal("a should be kept")
+	}
+}
+
+func TestCostEviction(t *testing.T) {
+	c := NewLRU(Options[string, string]{
+		MaxCost: 10,
+		Cost:    func(key string, value string) int64 { return int64(len(value)) },
+	})
+	c.Set("a", "12345")
+	c.Set("b", "1234")
+	if c.Cost() != 9 {
+		t.Fatalf("unexpected cost %d", c.Cost())
+	}
+	c.Set("c", "123")
+	if _, ok := c.Peek("a"); ok || c.Cost() != 7 {
+		t.Fatalf("a should be evicted, cost %d", c.Cost())
+	}
+	c.SetWithCost("big", "x", 11)
+	if _, ok := c.Peek("big"); ok {
+		t.Fatal("the entry larger than max cost should not be kept")
+	}
+	c.Set("b", "1")
+	if c.Cost() != 4 {
+		t.Fatalf("the cost should be updated on replace, got %d", c.Cost())
+	}
+}
+
+func TestTTLExpiry(t *testing.T) {
+	clock := &fakeClock{now: time.Unix(1700000000, 0)}
+	var expired []string
+	c := NewTTL(time.Minute, Options[string, int]{
+		Now: clock.Now,
+		OnEvict: func(key string, value int, reason EvictReason) {
+			if reason == EvictExpired {
+				expired = append(expired, key)
+			}
+		},
+	})
+	c.Set("a", 1)
+	c.SetWithTTL("b", 2, 0)
+	c.SetWithTTL("c", 3, 2*time.Minute)
+	clock.Advance(time.Minute)
+	if _, ok := c.Get("a"); ok {
+		t.Fatal("a should be expired")
+	}
+	if _, ok := c.Get("b"); !ok {
+		t.Fatal("b should never expire")
+	}
+	if keys := c.Keys(); len(keys) != 2 {
+		t.Fatalf("unexpected keys %v", keys)
+	}
+	clock.Advance(time.Minute)
+	if n := c.PurgeExpired(); n != 1 || c.Len() != 1 {
+		t.Fatalf("c should be purged, purged %d, len %d", n, c.Len())
+	}
+	if len(expired) != 2 {
+		t.Fatalf("unexpected expired %v", expired)
+	}
+}
+
+func TestDeleteAndClear(t *testing.T) {
+	var deleted int
+	c := NewLRU(Options[int, int]{
+		OnEvict: func(key int, value int, reason EvictReason) {
+			if reason == EvictDeleted {
+				deleted++
+			}
+		},
+	})
+	for i := 0; i < 5; i++ {
+		c.Set(i, i)
+	}
+	if !c.Delete(1) || c.Delete(1) {
+		t.Fatal("delete should report the existence")
+	}
+	c.Clear()
+	if c.Len() != 0 || c.Cost() != 0 || deleted != 5 {
+		t.Fatalf("unexpected state after clear, len %d, deleted %d", c.Len(), deleted)
+	}
+	if c.Stats().Evictions != 0 {
+		t.Fatal("delete should not be counted as eviction")
+	}
+}
+
+func TestGetOrLoadDeduplicates(t *testing.T) {
+	c := NewLRU(Options[string, int]{})
+	var loads atomic.Int32
+	release := make(chan struct{})
+	loader := func(ctx context.Context) (int, error) {
+		loads.Add(1)
+		<-release
+		return 42, nil
+	}
+
+	var wg sync.WaitGroup
+	results := make([]int, 10)
+	for i := range results {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			v, err := c.GetOrLoad(context.Background(), "answer", loader)
+			if err != nil {
+				t.Error(err)
+			}
+			results[i] = v
+		}(i)
+	}
+	time.Sleep(20 * time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	if loads.Load() != 1 {
+		t.Fatalf("loader should be called once, called %d", loads.Load())
+	}
+	for _, v := range results {
+		if v != 42 {
+			t.Fatalf("unexpected result %d", v)
+		}
+	}
+	if v, ok := c.Get("answer"); !ok || v != 42 {
+		t.Fatal("loaded value should be cached")
+	}
+	if stats := c.Stats(); stats.Loads != 1 {
+		t.Fatalf("unexpected stats %+v", stats)
+	}
+}
+
+func TestGetOrLoadError(t *testing.T) {
+	c := NewLRU(Options[string, int]{})
+	errLoad := errors.New("load failed")
+	_, err := c.GetOrLoad(context.Background(), "k", func(ctx context.Context) (int, error) {
+		return 0, errLoad
+	})
+	if !errors.Is(err, errLoad) {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if _, ok := c.Peek("k"); ok {
+		t.Fatal("failed load should not be cached")
+	}
+	_, err = c.GetOrLoad(context.Background(), "k", func(ctx context.Context) (int, error) {
+		panic("boom")
+	})
+	if err == nil {
+		t.Fatal("loader panic should be reported as error")
+	}
+	v, err := c.GetOrLoad(context.Background(), "k", func(ctx context.Context) (int, error) {
+		return 1, nil
+	})
+	if err != nil || v != 1 {
+		t.Fatalf("unexpected result %d %v", v, err)
+	}
+	if stats := c.Stats(); stats.LoadErrors != 2 || stats.Loads != 1 {
+		t.Fatalf("unexpected stats %+v", stats)
+	}
+}
+
+func TestGetOrLoadWaiterCanceled(t *testing.T) {
+	c := NewLRU(Options[string, int]{})
+	started := make(chan struct{})
+	release := make(chan struct{})
+	go c.GetOrLoad(context.Background(), "k", func(ctx context.Context) (int, error) {
+		close(started)
+		<-release
+		return 1, nil
+	})
+	<-started
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := c.GetOrLoad(ctx, "k", nil); !errors.Is(err, context.Canceled) {
+		t.Fatalf("waiter should stop on its own context, got %v", err)
+	}
+	close(release)
+}
+
+func TestConcurrentAccess(t *testing.T) {
+	for name, c := range map[string]*Cache[int, int]{
+		"lru": NewLRU(Options[int, int]{MaxEntries: 100}),
+		"lfu": NewLFU(Options[int, int]{MaxEntries: 100}),
+	} {
+		var wg sync.WaitGroup
+		for w := 0; w < 8; w++ {
+			wg.Add(1)
+			go func(w int) {
+				defer wg.Done()
+				for i := 0; i < 1000; i++ {
+					c.Set(w*1000+i, i)
+					c.Get(i)
+					if i%10 == 0 {
+						c.Delete(w*1000 + i - 1)
+					}
+				}
+			}(w)
+		}
+		wg.Wait()
+		if c.Len() > 100 {
+			t.Fatalf("%s cache: len %d exceeds max entries", name, c.Len())
+		}
+	}
+}
+
+func TestGetOrLoadKeepsNewerWrite(t *testing.T) {
+	c := NewLRU(Options[string, int]{})
+	started, release := make(chan struct{}), make(chan struct{})
+	done := make(chan int)
+	go func() {
+		v, _ := c.GetOrLoad(context.Background(), "k", func(ctx context.Context) (int, error) {
+			close(started)
+			<-release
+			return 1, nil
+		})
+		done <- v
+	}()
+	<-started
+	c.Set("k", 2)
+	close(release)
+	if v := <-done; v != 1 {
+		t.Fatalf("loader caller should get the loaded value, got %d", v)
+	}
+	if v, _ := c.Peek("k"); v != 2 {
+		t.Fatalf("loaded value should not overwrite the newer write, got %d", v)
+	}
+}
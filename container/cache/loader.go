@@ -0,0 +1,92 @@
+package cache
+
+import (
+	"context"
+	"fmt"
+	"sync"
+)
+
+// call is an in-flight load shared by the concurrent callers of the same key
+type call[V any] struct {
+	wg    sync.WaitGroup
+	value V
+	err   error
+	stale bool // the key is written during the load, so the loaded value is not cached
+}
+
+// GetOrLoad returns the cached value, or calls loader to load and cache it.
+//
+// The concurrent callers of the same key share one loader call, which runs with the
+// context of the first caller, and the other callers stop waiting when their own
+// context is done. The value is not cached when loader returns an error, or the key
+// is set or deleted during the load, since the loaded value may be older.
+func (c *Cache[K, V]) GetOrLoad(ctx context.Context, key K, loader func(ctx context.Context) (V, error)) (value V, err error) {
+	var evictions []evicted[K, V]
+	var ok bool
+	c.mu.Lock()
+	value, ok, evictions = c.getLocked(key, evictions)
+	if ok {
+		c.mu.Unlock()
+		c.notify(evictions)
+		return
+	}
+	if cl, loading := c.calls[key]; loading {
+		c.mu.Unlock()
+		c.notify(evictions)
+		return c.wait(ctx, cl)
+	}
+	cl := &call[V]{}
+	cl.wg.Add(1)
+	c.calls[key] = cl
+	c.mu.Unlock()
+	c.notify(evictions)
+
+	c.load(ctx, key, cl, loader)
+	return cl.value, cl.err
+}
+
+func (c *Cache[K, V]) wait(ctx context.Context, cl *call[V]) (value V, err error) {
+	done := make(chan struct{})
+	go func() {
+		cl.wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return cl.value, cl.err
+	case <-ctx.Done():
+		err = ctx.Err()
+		return
+	}
+}
+
+func (c *Cache[K, V]) load(ctx context.Context, key K, cl *call[V], loader func(ctx context.Context) (V, error)) {
+	var evictions []evicted[K, V]
+	defer func() {
+		// report the panic to the waiters instead of blocking them forever
+		if r := recover(); r != nil {
+			cl.err = fmt.Errorf("cache: loader panic, %v", r)
+		}
+		c.mu.Lock()
+		delete(c.calls, key)
+		if cl.err != nil {
+			c.stats.LoadErrors++
+		} else {
+			c.stats.Loads++
+			if !cl.stale {
+				evictions = c.setLocked(key, cl.value, c.costOf(key, cl.value), c.opts.TTL, evictions)
+			}
+		}
+		c.mu.Unlock()
+		cl.wg.Done()
+		c.notify(evictions)
+	}()
+	cl.value, cl.err = loader(ctx)
+}
+
+// invalidateLoadLocked keeps the in-flight load of the key from overwriting a newer write
+func (c *Cache[K, V]) invalidateLoadLocked(key K) {
+	if cl, loading := c.calls[key]; loading {
+		cl.stale = true
+	}
+}
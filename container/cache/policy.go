@@ -0,0 +1,120 @@
+package cache
+
+import (
+	"container/heap"
+	"container/list"
+)
+
+// policy decides which entry to evict when the cache is full,
+// an entry replaced by Set is removed and added again, which counts as an access.
+type policy[K comparable, V any] interface {
+	add(e *entry[K, V])
+	access(e *entry[K, V])
+	remove(e *entry[K, V])
+	victim() *entry[K, V]
+	clear()
+}
+
+// lruPolicy evicts the least recently used entry
+type lruPolicy[K comparable, V any] struct {
+	order *list.List
+}
+
+func newLRUPolicy[K comparable, V any]() *lruPolicy[K, V] {
+	return &lruPolicy[K, V]{order: list.New()}
+}
+
+func (p *lruPolicy[K, V]) add(e *entry[K, V]) {
+	e.elem = p.order.PushFront(e)
+}
+
+func (p *lruPolicy[K, V]) access(e *entry[K, V]) {
+	p.order.MoveToFront(e.elem)
+}
+
+func (p *lruPolicy[K, V]) remove(e *entry[K, V]) {
+	p.order.Remove(e.elem)
+}
+
+func (p *lruPolicy[K, V]) victim() *entry[K, V] {
+	if back := p.order.Back(); back != nil {
+		return back.Value.(*entry[K, V])
+	}
+	return nil
+}
+
+func (p *lruPolicy[K, V]) clear() {
+	p.order.Init()
+}
+
+// lfuPolicy evicts the least frequently used entry, the least recently used one among the same frequency
+type lfuPolicy[K comparable, V any] struct {
+	entries lfuHeap[K, V]
+	tick    uint64
+}
+
+func newLFUPolicy[K comparable, V any]() *lfuPolicy[K, V] {
+	return &lfuPolicy[K, V]{}
+}
+
+func (p *lfuPolicy[K, V]) add(e *entry[K, V]) {
+	// the frequency of a replaced entry is kept
+	p.tick++
+	e.freq++
+	e.tick = p.tick
+	heap.Push(&p.entries, e)
+}
+
+func (p *lfuPolicy[K, V]) access(e *entry[K, V]) {
+	p.tick++
+	e.freq++
+	e.tick = p.tick
+	heap.Fix(&p.entries, e.index)
+}
+
+func (p *lfuPolicy[K, V]) remove(e *entry[K, V]) {
+	heap.Remove(&p.entries, e.index)
+}
+
+func (p *lfuPolicy[K, V]) victim() *entry[K, V] {
+	if len(p.entries) == 0 {
+		return nil
+	}
+	return p.entries[0]
+}
+
+func (p *lfuPolicy[K, V]) clear() {
+	p.entries = p.entries[:0]
+}
+
+type lfuHeap[K comparable, V any] []*entry[K, V]
+
+func (h lfuHeap[K, V]) Len() int { return len(h) }
+
+func (h lfuHeap[K, V]) Less(i, j int) bool {
+	if h[i].freq != h[j].freq {
+		return h[i].freq < h[j].freq
+	}
+	return h[i].tick < h[j].tick
+}
+
+func (h lfuHeap[K, V]) Swap(i, j int) {
+	h[i], h[j] = h[j], h[i]
+	h[i].index = i
+	h[j].index = j
+}
+
+func (h *lfuHeap[K, V]) Push(x any) {
+	e := x.(*entry[K, V])
+	e.index = len(*h)
+	*h = append(*h, e)
+}
+
+func (h *lfuHeap[K, V]) Pop() any {
+	old := *h
+	n := len(old)
+	e := old[n-1]
+	old[n-1] = nil
+	*h = old[:n-1]
+	return e
+}
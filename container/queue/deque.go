@@ -0,0 +1,141 @@
+package queue
+
+import "iter"
+
+const minDequeCapacity = 8
+
+// Deque is a double-ended queue backed by a growable ring buffer
+type Deque[T any] struct {
+	buf  []T
+	head int
+	size int
+}
+
+// NewDeque returns a new deque with the values from front to back
+func NewDeque[T any](values ...T) *Deque[T] {
+	d := &Deque[T]{}
+	for _, v := range values {
+		d.PushBack(v)
+	}
+	return d
+}
+
+// Len returns the number of values in the deque
+func (d *Deque[T]) Len() int {
+	return d.size
+}
+
+func (d *Deque[T]) index(i int) int {
+	return (d.head + i) % len(d.buf)
+}
+
+func (d *Deque[T]) grow() {
+	if d.size < len(d.buf) {
+		return
+	}
+	buf := make([]T, max(2*len(d.buf), minDequeCapacity))
+	for i := 0; i < d.size; i++ {
+		buf[i] = d.buf[d.index(i)]
+	}
+	d.buf, d.head = buf, 0
+}
+
+// PushBack adds the value to the back
+func (d *Deque[T]) PushBack(value T) {
+	d.grow()
+	d.buf[d.index(d.size)] = value
+	d.size++
+}
+
+// PushFront adds the value to the front
+func (d *Deque[T]) PushFront(value T) {
+	d.grow()
+	d.head = (d.head - 1 + len(d.buf)) % len(d.buf)
+	d.buf[d.head] = value
+	d.size++
+}
+
+// PopFront removes and returns the front value
+func (d *Deque[T]) PopFront() (value T, ok bool) {
+	if d.size == 0 {
+		return
+	}
+	var zero T
+	value, d.buf[d.head] = d.buf[d.head], zero
+	d.head = d.index(1)
+	d.size--
+	return value, true
+}
+
+// PopBack removes and returns the back value
+func (d *Deque[T]) PopBack() (value T, ok bool) {
+	if d.size == 0 {
+		return
+	}
+	var zero T
+	i := d.index(d.size - 1)
+	value, d.buf[i] = d.buf[i], zero
+	d.size--
+	return value, true
+}
+
+// Front returns the front value without removing it
+func (d *Deque[T]) Front() (value T, ok bool) {
+	if d.size == 0 {
+		return
+	}
+	return d.buf[d.head], true
+}
+
+// Back returns the back value without removing it
+func (d *Deque[T]) Back() (value T, ok bool) {
+	if d.size == 0 {
+		return
+	}
+	return d.buf[d.index(d.size-1)], true
+}
+
+// At returns the i-th value from the front, it panics when i is out of range
+func (d *Deque[T]) At(i int) T {
+	if i < 0 || i >= d.size {
+		panic("queue: deque index out of range")
+	}
+	return d.buf[d.index(i)]
+}
+
+// Clear removes all the values
+func (d *Deque[T]) Clear() {
+	clear(d.buf)
+	d.head, d.size = 0, 0
+}
+
+// Values returns the values from front to back
+func (d *Deque[T]) Values() []T {
+	values := make([]T, d.size)
+	for i := range values {
+		values[i] = d.buf[d.index(i)]
+	}
+	return values
+}
+
+// All returns an iterator over the values from front to back
+func (d *Deque[T]) All() iter.Seq[T] {
+	return func(yield func(T) bool) {
+		for i := 0; i < d.size; i++ {
+			if !yield(d.buf[d.index(i)]) {
+				return
+			}
+		}
+	}
+}
+
+// Backward returns an iterator over the values from back to front
+func (d *Deque[T]) Backward() iter.Seq[T] {
+	return func(yield func(T) bool) {
+		for i := d.size - 1; i >= 0; i-- {
+			if !yield(d.buf[d.index(i)]) {
+				return
+			}
+		}
+	}
+}
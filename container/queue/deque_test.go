@@ -0,0 +1,60 @@
+package queue
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestDeque(t *testing.T) {
+	d := NewDeque(3, 4)
+	d.PushFront(2)
+	d.PushFront(1)
+	for i := 5; i <= 20; i++ {
+		d.PushBack(i)
+	}
+	if d.Len() != 20 || d.At(0) != 1 || d.At(19) != 20 {
+		t.Fatalf("unexpected deque %v", d.Values())
+	}
+	if v, _ := d.PopFront(); v != 1 {
+		t.Fatalf("unexpected front %d", v)
+	}
+	if v, _ := d.PopBack(); v != 20 {
+		t.Fatalf("unexpected back %d", v)
+	}
+	if f, _ := d.Front(); f != 2 {
+		t.Fatalf("unexpected front %d", f)
+	}
+	if b, _ := d.Back(); b != 19 {
+		t.Fatalf("unexpected back %d", b)
+	}
+	backward := slices.Collect(d.Backward())
+	slices.Reverse(backward)
+	if !slices.Equal(backward, slices.Collect(d.All())) {
+		t.Fatal("backward should be the reverse of all")
+	}
+	d.Clear()
+	if _, ok := d.PopBack(); ok || d.Len() != 0 {
+		t.Fatal("deque should be empty")
+	}
+}
+
+func TestDequeWrapAround(t *testing.T) {
+	d := NewDeque[int]()
+	var want []int
+	for i := 0; i < 100; i++ {
+		if i%3 == 0 {
+			d.PushFront(i)
+			want = slices.Insert(want, 0, i)
+		} else {
+			d.PushBack(i)
+			want = append(want, i)
+		}
+		if i%5 == 0 {
+			d.PopFront()
+			want = want[1:]
+		}
+	}
+	if !slices.Equal(d.Values(), want) {
+		t.Fatalf("unexpected values %v, want %v", d.Values(), want)
+	}
+}
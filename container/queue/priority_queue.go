@@ -0,0 +1,149 @@
+// Package queue provides generic priority queue, deque and ring buffer containers.
+//
+// None of the containers is routine-safe, guard them with a lock when shared.
+package queue
+
+import (
+	"container/heap"
+	"iter"
+)
+
+// Item is the handle of a value in the priority queue, which is used to update or remove it
+type Item[T any] struct {
+	Value T
+
+	index int
+	queue *PriorityQueue[T]
+}
+
+// PriorityQueue is a binary heap ordered by a less func, the least value is popped first
+type PriorityQueue[T any] struct {
+	items pqItems[T]
+}
+
+// NewPriorityQueue returns a new priority queue, less returns true when a should be popped before b
+func NewPriorityQueue[T any](less func(a, b T) bool, values ...T) *PriorityQueue[T] {
+	q := &PriorityQueue[T]{
+		items: pqItems[T]{less: less, items: make([]*Item[T], 0, len(values))},
+	}
+	for _, v := range values {
+		q.items.items = append(q.items.items, &Item[T]{Value: v, index: len(q.items.items), queue: q})
+	}
+	heap.Init(&q.items)
+	return q
+}
+
+// Len returns the number of values in the queue
+func (q *PriorityQueue[T]) Len() int {
+	return len(q.items.items)
+}
+
+// Push adds the value and returns its handle
+func (q *PriorityQueue[T]) Push(value T) *Item[T] {
+	item := &Item[T]{Value: value, queue: q}
+	heap.Push(&q.items, item)
+	return item
+}
+
+// Peek returns the least value without removing it
+func (q *PriorityQueue[T]) Peek() (value T, ok bool) {
+	if len(q.items.items) == 0 {
+		return
+	}
+	return q.items.items[0].Value, true
+}
+
+// Pop removes and returns the least value
+func (q *PriorityQueue[T]) Pop() (value T, ok bool) {
+	if len(q.items.items) == 0 {
+		return
+	}
+	item := heap.Pop(&q.items).(*Item[T])
+	return item.Value, true
+}
+
+// Contains returns true when the item is still in the queue
+func (q *PriorityQueue[T]) Contains(item *Item[T]) bool {
+	return item != nil && item.queue == q && item.index >= 0
+}
+
+// Update replaces the value of the item and restores the order,
+// it returns false when the item is not in the queue.
+func (q *PriorityQueue[T]) Update(item *Item[T], value T) bool {
+	if !q.Contains(item) {
+		return false
+	}
+	item.Value = value
+	heap.Fix(&q.items, item.index)
+	return true
+}
+
+// Fix restores the order after Item.Value is modified in place,
+// it returns false when the item is not in the queue.
+func (q *PriorityQueue[T]) Fix(item *Item[T]) bool {
+	if !q.Contains(item) {
+		return false
+	}
+	heap.Fix(&q.items, item.index)
+	return true
+}
+
+// Remove removes the item and returns false when it is not in the queue
+func (q *PriorityQueue[T]) Remove(item *Item[T]) bool {
+	if !q.Contains(item) {
+		return false
+	}
+	heap.Remove(&q.items, item.index)
+	return true
+}
+
+// Clear removes all the values, the handles become invalid
+func (q *PriorityQueue[T]) Clear() {
+	for _, item := range q.items.items {
+		item.index = -1
+	}
+	clear(q.items.items)
+	q.items.items = q.items.items[:0]
+}
+
+// Drain returns an iterator which pops the values in priority order
+func (q *PriorityQueue[T]) Drain() iter.Seq[T] {
+	return func(yield func(T) bool) {
+		for {
+			value, ok := q.Pop()
+			if !ok || !yield(value) {
+				return
+			}
+		}
+	}
+}
+
+type pqItems[T any] struct {
+	items []*Item[T]
+	less  func(a, b T) bool
+}
+
+func (h pqItems[T]) Len() int { return len(h.items) }
+
+func (h pqItems[T]) Less(i, j int) bool { return h.less(h.items[i].Value, h.items[j].Value) }
+
+func (h pqItems[T]) Swap(i, j int) {
+	h.items[i], h.items[j] = h.items[j], h.items[i]
+	h.items[i].index = i
+	h.items[j].index = j
+}
+
+func (h *pqItems[T]) Push(x any) {
+	item := x.(*Item[T])
+	item.index = len(h.items)
+	h.items = append(h.items, item)
+}
+
+func (h *pqItems[T]) Pop() any {
+	n := len(h.items)
+	item := h.items[n-1]
+	h.items[n-1] = nil
+	h.items = h.items[:n-1]
+	item.index = -1
+	return item
+}
@@ -0,0 +1,64 @@
+package queue
+
+import (
+	"slices"
+	"testing"
+)
+
+type task struct {
+	name     string
+	priority int
+}
+
+func TestPriorityQueueOrder(t *testing.T) {
+	q := NewPriorityQueue(func(a, b int) bool { return a < b }, 5, 1, 4)
+	q.Push(3)
+	q.Push(2)
+	if v, _ := q.Peek(); v != 1 {
+		t.Fatalf("unexpected peek %d", v)
+	}
+	if got := slices.Collect(q.Drain()); !slices.Equal(got, []int{1, 2, 3, 4, 5}) {
+		t.Fatalf("unexpected order %v", got)
+	}
+	if _, ok := q.Pop(); ok || q.Len() != 0 {
+		t.Fatal("queue should be empty")
+	}
+}
+
+func TestPriorityQueueHandle(t *testing.T) {
+	q := NewPriorityQueue(func(a, b task) bool { return a.priority > b.priority })
+	low := q.Push(task{"low", 1})
+	mid := q.Push(task{"mid", 5})
+	high := q.Push(task{"high", 10})
+
+	if !q.Update(low, task{"low", 20}) {
+		t.Fatal("update should succeed")
+	}
+	if v, _ := q.Peek(); v.name != "low" {
+		t.Fatalf("updated item should be the first, got %s", v.name)
+	}
+	mid.Value.priority = 30
+	q.Fix(mid)
+	if !q.Remove(high) || q.Remove(high) {
+		t.Fatal("remove should succeed only once")
+	}
+	if q.Update(high, task{}) || q.Contains(high) {
+		t.Fatal("removed handle should be invalid")
+	}
+	var names []string
+	for v := range q.Drain() {
+		names = append(names, v.name)
+	}
+	if !slices.Equal(names, []string{"mid", "low"}) {
+		t.Fatalf("unexpected order %v", names)
+	}
+	if q.Contains(mid) {
+		t.Fatal("popped handle should be invalid")
+	}
+
+	other := NewPriorityQueue(func(a, b task) bool { return a.priority > b.priority })
+	item := other.Push(task{"other", 1})
+	if q.Remove(item) {
+		t.Fatal("handle of another queue should be rejected")
+	}
+}
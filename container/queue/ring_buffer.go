@@ -0,0 +1,113 @@
+package queue
+
+import "iter"
+
+// RingBuffer is a fixed-capacity buffer keeping the latest values,
+// the oldest value is overwritten when the buffer is full.
+type RingBuffer[T any] struct {
+	buf  []T
+	head int // index of the oldest value
+	size int
+}
+
+// NewRingBuffer returns a new ring buffer, it panics when capacity is not positive
+func NewRingBuffer[T any](capacity int) *RingBuffer[T] {
+	if capacity <= 0 {
+		panic("queue: ring buffer capacity must be positive")
+	}
+	return &RingBuffer[T]{buf: make([]T, capacity)}
+}
+
+// Len returns the number of values in the buffer
+func (r *RingBuffer[T]) Len() int {
+	return r.size
+}
+
+// Cap returns the capacity of the buffer
+func (r *RingBuffer[T]) Cap() int {
+	return len(r.buf)
+}
+
+// Full returns true when the next Push overwrites the oldest value
+func (r *RingBuffer[T]) Full() bool {
+	return r.size == len(r.buf)
+}
+
+func (r *RingBuffer[T]) index(i int) int {
+	return (r.head + i) % len(r.buf)
+}
+
+// Push adds the value as the newest, and returns the overwritten oldest value when the buffer is full
+func (r *RingBuffer[T]) Push(value T) (overwritten T, ok bool) {
+	if r.Full() {
+		overwritten, ok = r.buf[r.head], true
+		r.buf[r.head] = value
+		r.head = r.index(1)
+		return
+	}
+	r.buf[r.index(r.size)] = value
+	r.size++
+	return
+}
+
+// Pop removes and returns the oldest value
+func (r *RingBuffer[T]) Pop() (value T, ok bool) {
+	if r.size == 0 {
+		return
+	}
+	var zero T
+	value, r.buf[r.head] = r.buf[r.head], zero
+	r.head = r.index(1)
+	r.size--
+	return value, true
+}
+
+// Oldest returns the oldest value without removing it
+func (r *RingBuffer[T]) Oldest() (value T, ok bool) {
+	if r.size == 0 {
+		return
+	}
+	return r.buf[r.head], true
+}
+
+// Newest returns the newest value without removing it
+func (r *RingBuffer[T]) Newest() (value T, ok bool) {
+	if r.size == 0 {
+		return
+	}
+	return r.buf[r.index(r.size-1)], true
+}
+
+// At returns the i-th value from the oldest, it panics when i is out of range
+func (r *RingBuffer[T]) At(i int) T {
+	if i < 0 || i >= r.size {
+		panic("queue: ring buffer index out of range")
+	}
+	return r.buf[r.index(i)]
+}
+
+// Clear removes all the values
+func (r *RingBuffer[T]) Clear() {
+	clear(r.buf)
+	r.head, r.size = 0, 0
+}
+
+// Values returns the values from the oldest to the newest
+func (r *RingBuffer[T]) Values() []T {
+	values := make([]T, r.size)
+	for i := range values {
+		values[i] = r.buf[r.index(i)]
+	}
+	return values
+}
+
+// All returns an iterator over the values from the oldest to the newest
+func (r *RingBuffer[T]) All() iter.Seq[T] {
+	return func(yield func(T) bool) {
+		for i := 0; i < r.size; i++ {
+			if !yield(r.buf[r.index(i)]) {
+				return
+			}
+		}
+	}
+}
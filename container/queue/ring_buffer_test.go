@@ -0,0 +1,52 @@
+package queue
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestRingBufferOverwrite(t *testing.T) {
+	r := NewRingBuffer[string](3)
+	for _, line := range []string{"a", "b", "c"} {
+		if _, ok := r.Push(line); ok {
+			t.Fatal("nothing should be overwritten before full")
+		}
+	}
+	if !r.Full() {
+		t.Fatal("buffer should be full")
+	}
+	if old, ok := r.Push("d"); !ok || old != "a" {
+		t.Fatalf("oldest should be overwritten, got %q", old)
+	}
+	r.Push("e")
+	if got := r.Values(); !slices.Equal(got, []string{"c", "d", "e"}) {
+		t.Fatalf("unexpected values %v", got)
+	}
+	if v, _ := r.Oldest(); v != "c" {
+		t.Fatalf("unexpected oldest %q", v)
+	}
+	if v, _ := r.Newest(); v != "e" {
+		t.Fatalf("unexpected newest %q", v)
+	}
+	if r.At(1) != "d" {
+		t.Fatalf("unexpected value at 1 %q", r.At(1))
+	}
+}
+
+func TestRingBufferPop(t *testing.T) {
+	r := NewRingBuffer[int](2)
+	r.Push(1)
+	r.Push(2)
+	r.Push(3)
+	if v, _ := r.Pop(); v != 2 {
+		t.Fatalf("unexpected pop %d", v)
+	}
+	r.Push(4)
+	if got := slices.Collect(r.All()); !slices.Equal(got, []int{3, 4}) {
+		t.Fatalf("unexpected values %v", got)
+	}
+	r.Clear()
+	if _, ok := r.Pop(); ok || r.Len() != 0 || r.Cap() != 2 {
+		t.Fatal("buffer should be empty")
+	}
+}
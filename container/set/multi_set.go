@@ -0,0 +1,210 @@
+package set
+
+import (
+	"cmp"
+	"encoding/json"
+	"fmt"
+	"iter"
+	"slices"
+)
+
+var _ Interface[int] = (*MultiSet[int])(nil)
+
+// MultiSetEntry is an elem with its count in the multiset
+type MultiSetEntry[T comparable] struct {
+	Elem  T   `json:"elem"`
+	Count int `json:"count"`
+}
+
+// MultiSet is a bag that counts the occurrences of the elems, it is not routine-safe.
+// The zero value is an empty multiset ready to use.
+//
+// As an Interface, it behaves like a set of the distinct elems: Len counts the distinct
+// elems and Delete removes all the occurrences, use Total and Remove for the counts.
+type MultiSet[T comparable] struct {
+	counts map[T]int
+	total  int
+}
+
+// NewMultiSet returns a new multiset, each elem is counted once per occurrence
+func NewMultiSet[T comparable](elems ...T) *MultiSet[T] {
+	s := &MultiSet[T]{
+		counts: make(map[T]int, len(elems)),
+	}
+	for _, e := range elems {
+		s.Add(e)
+	}
+	return s
+}
+
+// CollectMultiSet returns a new multiset counting the elems of the sequence
+func CollectMultiSet[T comparable](seq iter.Seq[T]) *MultiSet[T] {
+	s := NewMultiSet[T]()
+	for e := range seq {
+		s.Add(e)
+	}
+	return s
+}
+
+// Contains returns true when the count of elem is positive
+func (s *MultiSet[T]) Contains(elem T) bool {
+	return s.counts[elem] > 0
+}
+
+// Count returns the occurrences of elem
+func (s *MultiSet[T]) Count(elem T) int {
+	return s.counts[elem]
+}
+
+// Len returns the number of distinct elems
+func (s *MultiSet[T]) Len() int {
+	return len(s.counts)
+}
+
+// Total returns the sum of the occurrences of all elems
+func (s *MultiSet[T]) Total() int {
+	return s.total
+}
+
+// Elems returns the distinct elems in random order
+func (s *MultiSet[T]) Elems() []T {
+	elems := make([]T, 0, len(s.counts))
+	for k := range s.counts {
+		elems = append(elems, k)
+	}
+	return elems
+}
+
+// All returns an iterator over the distinct elems in random order
+func (s *MultiSet[T]) All() iter.Seq[T] {
+	return func(yield func(T) bool) {
+		for k := range s.counts {
+			if !yield(k) {
+				return
+			}
+		}
+	}
+}
+
+// Counts returns an iterator over the distinct elems with their counts
+func (s *MultiSet[T]) Counts() iter.Seq2[T, int] {
+	return func(yield func(T, int) bool) {
+		for k, v := range s.counts {
+			if !yield(k, v) {
+				return
+			}
+		}
+	}
+}
+
+// Add adds one occurrence of elem and returns the new count
+func (s *MultiSet[T]) Add(elem T) int {
+	return s.AddN(elem, 1)
+}
+
+// AddN adds n occurrences of elem and returns the new count, n should be positive
+func (s *MultiSet[T]) AddN(elem T, n int) int {
+	if n <= 0 {
+		return s.counts[elem]
+	}
+	if s.counts == nil {
+		s.counts = make(map[T]int)
+	}
+	s.counts[elem] += n
+	s.total += n
+	return s.counts[elem]
+}
+
+// Remove removes at most n occurrences of elem and returns the new count
+func (s *MultiSet[T]) Remove(elem T, n int) int {
+	count, exists := s.counts[elem]
+	if !exists || n <= 0 {
+		return count
+	}
+	if n >= count {
+		delete(s.counts, elem)
+		s.total -= count
+		return 0
+	}
+	s.counts[elem] = count - n
+	s.total -= n
+	return count - n
+}
+
+// AddIfAbsent adds one occurrence and returns true when elem is not in the multiset
+func (s *MultiSet[T]) AddIfAbsent(elem T) bool {
+	if s.Contains(elem) {
+		return false
+	}
+	s.Add(elem)
+	return true
+}
+
+// Delete removes all the occurrences and returns true when elem was in the multiset
+func (s *MultiSet[T]) Delete(elem T) bool {
+	count, exists := s.counts[elem]
+	if !exists {
+		return false
+	}
+	delete(s.counts, elem)
+	s.total -= count
+	return true
+}
+
+// PopAny removes all the occurrences of an arbitrary elem and returns it
+func (s *MultiSet[T]) PopAny() (elem T, ok bool) {
+	for k := range s.counts {
+		s.Delete(k)
+		return k, true
+	}
+	return
+}
+
+// Clear removes all the elems from the multiset
+func (s *MultiSet[T]) Clear() {
+	clear(s.counts)
+	s.total = 0
+}
+
+// MostCommon returns the n most common elems with their counts in descending order,
+// all the elems are returned when n is not positive. Elems with the same count are in random order.
+func (s *MultiSet[T]) MostCommon(n int) []MultiSetEntry[T] {
+	entries := make([]MultiSetEntry[T], 0, len(s.counts))
+	for k, v := range s.counts {
+		entries = append(entries, MultiSetEntry[T]{Elem: k, Count: v})
+	}
+	slices.SortStableFunc(entries, func(a, b MultiSetEntry[T]) int { return cmp.Compare(b.Count, a.Count) })
+	if n > 0 && n < len(entries) {
+		entries = entries[:n]
+	}
+	return entries
+}
+
+// ToSet converts the distinct elems to a plain set
+func (s *MultiSet[T]) ToSet() *Set[T] {
+	return Collect(s.All())
+}
+
+// MarshalJSON encodes the multiset as a json array of entries in descending count order
+func (s *MultiSet[T]) MarshalJSON() ([]byte, error) {
+	return json.Marshal(s.MostCommon(0))
+}
+
+// UnmarshalJSON decodes the multiset from a json array of entries, the counts should be positive
+func (s *MultiSet[T]) UnmarshalJSON(data []byte) error {
+	var entries []MultiSetEntry[T]
+	if err := json.Unmarshal(data, &entries); err != nil {
+		return err
+	}
+	counts := make(map[T]int, len(entries))
+	total := 0
+	for _, entry := range entries {
+		if entry.Count <= 0 {
+			return fmt.Errorf("set: invalid count %d of multiset entry", entry.Count)
+		}
+		counts[entry.Elem] += entry.Count
+		total += entry.Count
+	}
+	s.counts, s.total = counts, total
+	return nil
+}
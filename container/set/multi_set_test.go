@@ -0,0 +1,44 @@
+package set
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestMultiSet(t *testing.T) {
+	s := NewMultiSet("env:prod", "team:a", "env:prod", "env:dev", "env:prod")
+	if s.Count("env:prod") != 3 || s.Len() != 3 || s.Total() != 5 {
+		t.Fatalf("invalid counts, len=%d, total=%d", s.Len(), s.Total())
+	}
+	s.AddN("team:a", 2)
+	if common := s.MostCommon(2); common[0].Elem != "env:prod" || common[1].Elem != "team:a" || common[1].Count != 3 {
+		t.Fatalf("invalid most common %v", common)
+	}
+	if s.Remove("env:prod", 2) != 1 || s.Remove("env:dev", 5) != 0 || s.Contains("env:dev") {
+		t.Fatal("invalid remove")
+	}
+	if s.Total() != 4 || s.Len() != 2 {
+		t.Fatalf("invalid counts after remove, len=%d, total=%d", s.Len(), s.Total())
+	}
+	if !s.ToSet().Equal(New("env:prod", "team:a")) {
+		t.Fatal("invalid conversion to set")
+	}
+
+	counted := CollectMultiSet(NewOrderedSet(1, 2).All())
+	counted.Add(2)
+	data, _ := json.Marshal(counted)
+	if string(data) != `[{"elem":2,"count":2},{"elem":1,"count":1}]` {
+		t.Fatalf("invalid json %s", data)
+	}
+	var decoded MultiSet[int]
+	if err := json.Unmarshal(data, &decoded); err != nil || decoded.Count(2) != 2 || decoded.Total() != 3 {
+		t.Fatalf("invalid decoded multiset %v", decoded.MostCommon(0))
+	}
+	if err := json.Unmarshal([]byte(`[{"elem":1,"count":0}]`), &decoded); err == nil {
+		t.Fatal("non-positive count should be rejected")
+	}
+	s.Clear()
+	if s.Total() != 0 || s.Len() != 0 {
+		t.Fatal("multiset should be empty after clear")
+	}
+}
@@ -0,0 +1,153 @@
+package set
+
+import (
+	"container/list"
+	"encoding/json"
+	"iter"
+)
+
+var _ Interface[int] = (*OrderedSet[int])(nil)
+
+// OrderedSet is a set that remembers the insertion order of the elems, it is not routine-safe.
+// The zero value is an empty set ready to use.
+type OrderedSet[T comparable] struct {
+	index map[T]*list.Element
+	order *list.List
+}
+
+// NewOrderedSet returns a new insertion-ordered set
+func NewOrderedSet[T comparable](elems ...T) *OrderedSet[T] {
+	s := &OrderedSet[T]{
+		index: make(map[T]*list.Element, len(elems)),
+		order: list.New(),
+	}
+	return s.Append(elems...)
+}
+
+func (s *OrderedSet[T]) lazyInit() {
+	if s.order == nil {
+		s.index = make(map[T]*list.Element)
+		s.order = list.New()
+	}
+}
+
+// CollectOrdered returns a new insertion-ordered set with the elems of the sequence
+func CollectOrdered[T comparable](seq iter.Seq[T]) *OrderedSet[T] {
+	s := NewOrderedSet[T]()
+	for e := range seq {
+		s.AddIfAbsent(e)
+	}
+	return s
+}
+
+// Contains returns true when elem in the set
+func (s *OrderedSet[T]) Contains(elem T) bool {
+	_, exists := s.index[elem]
+	return exists
+}
+
+// Len returns the number of elems in the set
+func (s *OrderedSet[T]) Len() int {
+	return len(s.index)
+}
+
+// Elems returns the elems in insertion order
+func (s *OrderedSet[T]) Elems() []T {
+	elems := make([]T, 0, len(s.index))
+	for e := range s.All() {
+		elems = append(elems, e)
+	}
+	return elems
+}
+
+// All returns an iterator over the elems in insertion order
+func (s *OrderedSet[T]) All() iter.Seq[T] {
+	return func(yield func(T) bool) {
+		s.lazyInit()
+		for node := s.order.Front(); node != nil; node = node.Next() {
+			if !yield(node.Value.(T)) {
+				return
+			}
+		}
+	}
+}
+
+// Backward returns an iterator over the elems in reverse insertion order
+func (s *OrderedSet[T]) Backward() iter.Seq[T] {
+	return func(yield func(T) bool) {
+		s.lazyInit()
+		for node := s.order.Back(); node != nil; node = node.Prev() {
+			if !yield(node.Value.(T)) {
+				return
+			}
+		}
+	}
+}
+
+// Append appends the elems into the set and return itself, the existing elems keep their positions
+func (s *OrderedSet[T]) Append(elems ...T) *OrderedSet[T] {
+	for _, e := range elems {
+		s.AddIfAbsent(e)
+	}
+	return s
+}
+
+// AddIfAbsent adds the elem to the back and returns true when it is not in the set
+func (s *OrderedSet[T]) AddIfAbsent(elem T) bool {
+	s.lazyInit()
+	if _, exists := s.index[elem]; exists {
+		return false
+	}
+	s.index[elem] = s.order.PushBack(elem)
+	return true
+}
+
+// Delete removes the elem and returns true when it was in the set
+func (s *OrderedSet[T]) Delete(elem T) bool {
+	node, exists := s.index[elem]
+	if !exists {
+		return false
+	}
+	s.order.Remove(node)
+	delete(s.index, elem)
+	return true
+}
+
+// PopAny removes and returns the earliest inserted elem
+func (s *OrderedSet[T]) PopAny() (elem T, ok bool) {
+	s.lazyInit()
+	node := s.order.Front()
+	if node == nil {
+		return
+	}
+	elem = node.Value.(T)
+	s.Delete(elem)
+	return elem, true
+}
+
+// Clear removes all the elems from the set
+func (s *OrderedSet[T]) Clear() {
+	s.lazyInit()
+	clear(s.index)
+	s.order.Init()
+}
+
+// ToSet converts to a plain set
+func (s *OrderedSet[T]) ToSet() *Set[T] {
+	return Collect(s.All())
+}
+
+// MarshalJSON encodes the set as a json array in insertion order
+func (s *OrderedSet[T]) MarshalJSON() ([]byte, error) {
+	return json.Marshal(s.Elems())
+}
+
+// UnmarshalJSON decodes the set from a json array, the array order is kept
+func (s *OrderedSet[T]) UnmarshalJSON(data []byte) error {
+	var elems []T
+	if err := json.Unmarshal(data, &elems); err != nil {
+		return err
+	}
+	*s = *NewOrderedSet(elems...)
+	return nil
+}
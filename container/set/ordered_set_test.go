@@ -0,0 +1,71 @@
+package set
+
+import (
+	"encoding/json"
+	"reflect"
+	"slices"
+	"testing"
+)
+
+func TestOrderedSet(t *testing.T) {
+	s := NewOrderedSet("go", "base", "set", "go")
+	s.Append("tree", "base")
+	if !reflect.DeepEqual(s.Elems(), []string{"go", "base", "set", "tree"}) {
+		t.Fatalf("elems should keep insertion order, got %v", s.Elems())
+	}
+	if !reflect.DeepEqual(slices.Collect(s.Backward()), []string{"tree", "set", "base", "go"}) {
+		t.Fatal("invalid backward iteration")
+	}
+	s.Delete("base")
+	if elem, ok := s.PopAny(); !ok || elem != "go" {
+		t.Fatalf("pop should return the earliest elem, got %s", elem)
+	}
+	if s.Len() != 2 || s.Contains("go") {
+		t.Fatalf("unexpected elems %v", s.Elems())
+	}
+
+	data, _ := json.Marshal(s)
+	if string(data) != `["set","tree"]` {
+		t.Fatalf("invalid json %s", data)
+	}
+	decoded := NewOrderedSet[string]()
+	if err := json.Unmarshal([]byte(`["b","a","b"]`), decoded); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(decoded.Elems(), []string{"b", "a"}) {
+		t.Fatalf("invalid decoded set %v", decoded.Elems())
+	}
+	if !decoded.ToSet().Equal(New("a", "b")) {
+		t.Fatal("invalid conversion to set")
+	}
+	s.Clear()
+	if s.Len() != 0 || len(s.Elems()) != 0 {
+		t.Fatal("set should be empty after clear")
+	}
+
+	var zero OrderedSet[int]
+	if len(zero.Elems()) != 0 || !zero.AddIfAbsent(1) || !zero.Contains(1) {
+		t.Fatal("zero ordered set should be ready to use")
+	}
+}
+
+func TestSwapImplementations(t *testing.T) {
+	impls := map[string]Interface[int]{
+		"set":      New[int](),
+		"ordered":  NewOrderedSet[int](),
+		"sorted":   NewSortedSet[int](),
+		"multiset": NewMultiSet[int](),
+		"sync":     NewSyncSet[int](),
+	}
+	for name, s := range impls {
+		for _, e := range []int{3, 1, 2, 3} {
+			s.AddIfAbsent(e)
+		}
+		if s.Len() != 3 || !s.Contains(2) || s.Delete(4) || !s.Delete(2) || s.Contains(2) {
+			t.Fatalf("%s: unexpected behaviour, elems %v", name, s.Elems())
+		}
+		if !Collect(s.All()).Equal(New(1, 3)) {
+			t.Fatalf("%s: unexpected elems %v", name, s.Elems())
+		}
+	}
+}
@@ -0,0 +1,305 @@
+package set
+
+import (
+	"cmp"
+	"encoding/json"
+	"iter"
+	"reflect"
+	"slices"
+)
+
+// Interface is the behaviour shared by Set, SyncSet and ShardedSet,
+// so the callers can switch to the routine-safe ones without changes.
+type Interface[T comparable] interface {
+	Contains(elem T) bool
+	Len() int
+	Elems() []T
+	All() iter.Seq[T]
+	AddIfAbsent(elem T) bool
+	Delete(elem T) bool
+	PopAny() (T, bool)
+	Clear()
+}
+
+var _ Interface[int] = (*Set[int])(nil)
+
+// Set is a generic set, it is not routine-safe.
+// The zero value is an empty set ready to use.
+type Set[T comparable] struct {
+	setElems map[T]struct{}
+}
+
+// New returns a new set
+func New[T comparable](elems ...T) *Set[T] {
+	setElems := make(map[T]struct{}, len(elems))
+	for _, e := range elems {
+		setElems[e] = struct{}{}
+	}
+	return &Set[T]{
+		setElems: setElems,
+	}
+}
+
+// NewFunc returns a new set with a decoration
+func NewFunc[T comparable](decoration func(T) T, elems ...T) *Set[T] {
+	setElems := make(map[T]struct{}, len(elems))
+	for _, e := range elems {
+		setElems[decoration(e)] = struct{}{}
+	}
+	return &Set[T]{
+		setElems: setElems,
+	}
+}
+
+// Collect returns a new set with the elems of the sequence
+func Collect[T comparable](seq iter.Seq[T]) *Set[T] {
+	s := New[T]()
+	for e := range seq {
+		s.setElems[e] = struct{}{}
+	}
+	return s
+}
+
+// Contains returns true when elem in the set
+func (s *Set[T]) Contains(elem T) bool {
+	_, exists := s.setElems[elem]
+	return exists
+}
+
+// Exists checks whether the specified elem exists in set.
+func (s *Set[T]) Exists(elem T) bool {
+	return s.Contains(elem)
+}
+
+// Len returns the number of elems in the set
+func (s *Set[T]) Len() int {
+	return len(s.setElems)
+}
+
+// Elems returns the elems of the set in random order
+func (s *Set[T]) Elems() []T {
+	elems := make([]T, 0, len(s.setElems))
+	for k := range s.setElems {
+		elems = append(elems, k)
+	}
+	return elems
+}
+
+// ElemsFunc returns the elems that meet the specified func
+func (s *Set[T]) ElemsFunc(fn func(T) bool) []T {
+	elems := make([]T, 0, len(s.setElems))
+	for k := range s.setElems {
+		if fn(k) {
+			elems = append(elems, k)
+		}
+	}
+	return elems
+}
+
+// All returns an iterator over the elems in random order
+func (s *Set[T]) All() iter.Seq[T] {
+	return func(yield func(T) bool) {
+		for k := range s.setElems {
+			if !yield(k) {
+				return
+			}
+		}
+	}
+}
+
+// Append appends the elems into the set and return itself
+func (s *Set[T]) Append(elems ...T) *Set[T] {
+	if s.setElems == nil {
+		s.setElems = make(map[T]struct{}, len(elems))
+	}
+	for _, e := range elems {
+		s.setElems[e] = struct{}{}
+	}
+	return s
+}
+
+// Remove removes the elems from the set and return itself
+func (s *Set[T]) Remove(elems ...T) *Set[T] {
+	for _, e := range elems {
+		delete(s.setElems, e)
+	}
+	return s
+}
+
+// AddIfAbsent adds the elem and returns true when it is not in the set
+func (s *Set[T]) AddIfAbsent(elem T) bool {
+	if s.Contains(elem) {
+		return false
+	}
+	s.Append(elem)
+	return true
+}
+
+// Delete removes the elem and returns true when it was in the set
+func (s *Set[T]) Delete(elem T) bool {
+	if !s.Contains(elem) {
+		return false
+	}
+	delete(s.setElems, elem)
+	return true
+}
+
+// PopAny removes and returns an arbitrary elem, ok is false when the set is empty
+func (s *Set[T]) PopAny() (elem T, ok bool) {
+	for k := range s.setElems {
+		delete(s.setElems, k)
+		return k, true
+	}
+	return
+}
+
+// Clear removes all the elems from the set
+func (s *Set[T]) Clear() {
+	clear(s.setElems)
+}
+
+// Clone returns a copy of the set
+func (s *Set[T]) Clone() *Set[T] {
+	cloneElems := make(map[T]struct{}, len(s.setElems))
+	for k := range s.setElems {
+		cloneElems[k] = struct{}{}
+	}
+	return &Set[T]{
+		setElems: cloneElems,
+	}
+}
+
+// Union returns a new set which holds the union elems of both set
+func (s *Set[T]) Union(p *Set[T]) *Set[T] {
+	unionElems := make(map[T]struct{}, len(s.setElems)+len(p.setElems))
+	for k := range s.setElems {
+		unionElems[k] = struct{}{}
+	}
+	for k := range p.setElems {
+		unionElems[k] = struct{}{}
+	}
+	return &Set[T]{
+		setElems: unionElems,
+	}
+}
+
+// Intersect returns a new set which holds the elems intersect of both set
+func (s *Set[T]) Intersect(p *Set[T]) *Set[T] {
+	// iterate the smaller one
+	small, large := s, p
+	if small.Len() > large.Len() {
+		small, large = large, small
+	}
+	intersectElems := make(map[T]struct{})
+	for k := range small.setElems {
+		if _, ok := large.setElems[k]; ok {
+			intersectElems[k] = struct{}{}
+		}
+	}
+	return &Set[T]{
+		setElems: intersectElems,
+	}
+}
+
+// Difference returns a new set which holds the elems in s but not in p
+func (s *Set[T]) Difference(p *Set[T]) *Set[T] {
+	diffElems := make(map[T]struct{})
+	for k := range s.setElems {
+		if _, ok := p.setElems[k]; !ok {
+			diffElems[k] = struct{}{}
+		}
+	}
+	return &Set[T]{
+		setElems: diffElems,
+	}
+}
+
+// SymmetricDifference returns a new set which holds the elems in either s or p but not both
+func (s *Set[T]) SymmetricDifference(p *Set[T]) *Set[T] {
+	diffElems := make(map[T]struct{})
+	for k := range s.setElems {
+		if _, ok := p.setElems[k]; !ok {
+			diffElems[k] = struct{}{}
+		}
+	}
+	for k := range p.setElems {
+		if _, ok := s.setElems[k]; !ok {
+			diffElems[k] = struct{}{}
+		}
+	}
+	return &Set[T]{
+		setElems: diffElems,
+	}
+}
+
+// IsSubset returns true when all the elems of s are in p
+func (s *Set[T]) IsSubset(p *Set[T]) bool {
+	if s.Len() > p.Len() {
+		return false
+	}
+	for k := range s.setElems {
+		if _, ok := p.setElems[k]; !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// IsSuperset returns true when all the elems of p are in s
+func (s *Set[T]) IsSuperset(p *Set[T]) bool {
+	return p.IsSubset(s)
+}
+
+// Equal returns true when both set hold the same elems
+func (s *Set[T]) Equal(p *Set[T]) bool {
+	return s.Len() == p.Len() && s.IsSubset(p)
+}
+
+// MarshalJSON encodes the set as a json array, the elems of basic kinds are sorted
+func (s *Set[T]) MarshalJSON() ([]byte, error) {
+	elems := s.Elems()
+	sortBasicElems(elems)
+	return json.Marshal(elems)
+}
+
+// UnmarshalJSON decodes the set from a json array
+func (s *Set[T]) UnmarshalJSON(data []byte) error {
+	var elems []T
+	if err := json.Unmarshal(data, &elems); err != nil {
+		return err
+	}
+	s.setElems = make(map[T]struct{}, len(elems))
+	s.Append(elems...)
+	return nil
+}
+
+// Sorted returns the elems of the set in ascending order
+func Sorted[T cmp.Ordered](s *Set[T]) []T {
+	elems := s.Elems()
+	slices.Sort(elems)
+	return elems
+}
+
+// sortBasicElems sorts the elems whose underlying kind is integer, float or string,
+// which makes the json output stable, other kinds are left as is.
+func sortBasicElems[T comparable](elems []T) {
+	if len(elems) < 2 {
+		return
+	}
+	var less func(a, b reflect.Value) int
+	switch reflect.TypeFor[T]().Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		less = func(a, b reflect.Value) int { return cmp.Compare(a.Int(), b.Int()) }
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		less = func(a, b reflect.Value) int { return cmp.Compare(a.Uint(), b.Uint()) }
+	case reflect.Float32, reflect.Float64:
+		less = func(a, b reflect.Value) int { return cmp.Compare(a.Float(), b.Float()) }
+	case reflect.String:
+		less = func(a, b reflect.Value) int { return cmp.Compare(a.String(), b.String()) }
+	default:
+		return
+	}
+	slices.SortFunc(elems, func(a, b T) int {
+		return less(reflect.ValueOf(a), reflect.ValueOf(b))
+	})
+}
@@ -0,0 +1,115 @@
+package set
+
+import (
+	"encoding/json"
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestSetAlgebra(t *testing.T) {
+	a := New(1, 2, 3, 4)
+	b := New(3, 4, 5)
+
+	samples := []struct {
+		name     string
+		got      *Set[int]
+		expected []int
+	}{
+		{"union", a.Union(b), []int{1, 2, 3, 4, 5}},
+		{"intersect", a.Intersect(b), []int{3, 4}},
+		{"difference", a.Difference(b), []int{1, 2}},
+		{"symmetric difference", a.SymmetricDifference(b), []int{1, 2, 5}},
+	}
+	for _, sample := range samples {
+		if got := Sorted(sample.got); !reflect.DeepEqual(got, sample.expected) {
+			t.Fatalf("invalid %s, got %v", sample.name, got)
+		}
+	}
+
+	if !New(3, 4).IsSubset(a) || a.IsSubset(b) || !a.IsSuperset(New(1)) {
+		t.Fatal("invalid subset check")
+	}
+	if !a.Equal(New(4, 3, 2, 1)) || a.Equal(b) {
+		t.Fatal("invalid equal check")
+	}
+}
+
+func TestSetModify(t *testing.T) {
+	var s Set[string]
+	s.Append("a", "b", "c").Remove("b")
+	if s.Len() != 2 || s.Contains("b") || !s.Exists("a") {
+		t.Fatalf("invalid set elems %v", s.Elems())
+	}
+	clone := s.Clone()
+	s.Clear()
+	if s.Len() != 0 || clone.Len() != 2 {
+		t.Fatal("clone should not be affected by clear")
+	}
+	upper := NewFunc(strings.ToUpper, "a", "A", "b")
+	if !reflect.DeepEqual(Sorted(upper), []string{"A", "B"}) {
+		t.Fatalf("invalid decorated set %v", upper.Elems())
+	}
+}
+
+func TestSetIter(t *testing.T) {
+	s := New(1, 2, 3)
+	collected := Collect(s.All())
+	if !collected.Equal(s) {
+		t.Fatal("collected set should equal the source")
+	}
+	elems := slices.Sorted(s.All())
+	if !reflect.DeepEqual(elems, []int{1, 2, 3}) {
+		t.Fatalf("invalid iteration %v", elems)
+	}
+	for range s.All() {
+		break
+	}
+}
+
+func TestSetJSON(t *testing.T) {
+	type point struct{ X, Y int }
+	data, err := json.Marshal(map[string]any{
+		"ints":   New(3, 1, 2),
+		"tags":   New("go", "base"),
+		"points": New(point{1, 2}),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != `{"ints":[1,2,3],"points":[{"X":1,"Y":2}],"tags":["base","go"]}` {
+		t.Fatalf("invalid json %s", data)
+	}
+
+	var decoded struct {
+		Ints *Set[int] `json:"ints"`
+		Tags StringSet `json:"tags"`
+		More *IntSet   `json:"more"`
+	}
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatal(err)
+	}
+	if !decoded.Ints.Equal(New(1, 2, 3)) || !decoded.Tags.Contains("go") || decoded.More != nil {
+		t.Fatalf("invalid decoded set %+v", decoded)
+	}
+}
+
+func TestIntAndStringSet(t *testing.T) {
+	a := NewIntSet(1, 2, 3)
+	b := NewIntSetFunc(func(i int) int { return i * 2 }, 1, 2)
+	if !reflect.DeepEqual(Sorted(a.Union(b)), []int{1, 2, 3, 4}) {
+		t.Fatal("invalid int set union")
+	}
+	if !reflect.DeepEqual(a.Intersect(b).Elems(), []int{2}) || a.Difference(b).Len() != 2 {
+		t.Fatal("invalid int set intersect or difference")
+	}
+	var c *IntSet = a.Clone()
+	if !a.SymmetricDifference(b).Equal(NewIntSet(1, 3, 4)) || !c.Equal(a) || !NewIntSet(2).IsSubset(b) || !a.IsSuperset(NewIntSet(1, 2)) {
+		t.Fatal("invalid int set symmetric difference, clone or subset")
+	}
+	s := NewStringSet("a").Append("b")
+	if !s.Exists("b") || !reflect.DeepEqual(s.ElemsFunc(func(e string) bool { return e == "a" }), []string{"a"}) {
+		t.Fatal("invalid string set")
+	}
+}
@@ -0,0 +1,219 @@
+package set
+
+import (
+	"cmp"
+	"encoding/json"
+	"errors"
+	"iter"
+	"slices"
+)
+
+var _ Interface[int] = (*SortedSet[int])(nil)
+
+// SortedSet is a set that keeps the elems in ascending order by a compare func,
+// backed by a sorted slice, it is not routine-safe.
+//
+// The set should be created by NewSortedSet or NewSortedSetFunc, which set the compare func,
+// adding elems to a zero value panics.
+type SortedSet[T comparable] struct {
+	elems   []T
+	compare func(a, b T) int
+}
+
+// NewSortedSet returns a new sorted set of the ordered type
+func NewSortedSet[T cmp.Ordered](elems ...T) *SortedSet[T] {
+	return NewSortedSetFunc(cmp.Compare[T], elems...)
+}
+
+// NewSortedSetFunc returns a new sorted set ordered by the compare func,
+// which returns a negative number when a < b, zero when a == b and a positive number when a > b.
+func NewSortedSetFunc[T comparable](compare func(a, b T) int, elems ...T) *SortedSet[T] {
+	s := &SortedSet[T]{
+		elems:   slices.Clone(elems),
+		compare: compare,
+	}
+	slices.SortFunc(s.elems, compare)
+	s.elems = slices.CompactFunc(s.elems, func(a, b T) bool { return compare(a, b) == 0 })
+	return s
+}
+
+// CollectSorted returns a new sorted set with the elems of the sequence
+func CollectSorted[T cmp.Ordered](seq iter.Seq[T]) *SortedSet[T] {
+	return NewSortedSet(slices.Collect(seq)...)
+}
+
+func (s *SortedSet[T]) search(elem T) (int, bool) {
+	return slices.BinarySearchFunc(s.elems, elem, s.compare)
+}
+
+// Contains returns true when elem in the set
+func (s *SortedSet[T]) Contains(elem T) bool {
+	_, found := s.search(elem)
+	return found
+}
+
+// Len returns the number of elems in the set
+func (s *SortedSet[T]) Len() int {
+	return len(s.elems)
+}
+
+// Elems returns the elems in ascending order
+func (s *SortedSet[T]) Elems() []T {
+	return slices.Clone(s.elems)
+}
+
+// All returns an iterator over the elems in ascending order
+func (s *SortedSet[T]) All() iter.Seq[T] {
+	return slices.Values(s.elems)
+}
+
+// Backward returns an iterator over the elems in descending order
+func (s *SortedSet[T]) Backward() iter.Seq[T] {
+	return func(yield func(T) bool) {
+		for i := len(s.elems) - 1; i >= 0; i-- {
+			if !yield(s.elems[i]) {
+				return
+			}
+		}
+	}
+}
+
+// Append appends the elems into the set and return itself
+func (s *SortedSet[T]) Append(elems ...T) *SortedSet[T] {
+	for _, e := range elems {
+		s.AddIfAbsent(e)
+	}
+	return s
+}
+
+// AddIfAbsent inserts the elem in order and returns true when it is not in the set
+func (s *SortedSet[T]) AddIfAbsent(elem T) bool {
+	if s.compare == nil {
+		panic("set: SortedSet is not created by NewSortedSet or NewSortedSetFunc")
+	}
+	i, found := s.search(elem)
+	if found {
+		return false
+	}
+	s.elems = slices.Insert(s.elems, i, elem)
+	return true
+}
+
+// Delete removes the elem and returns true when it was in the set
+func (s *SortedSet[T]) Delete(elem T) bool {
+	i, found := s.search(elem)
+	if !found {
+		return false
+	}
+	s.elems = slices.Delete(s.elems, i, i+1)
+	return true
+}
+
+// PopAny removes and returns the min elem
+func (s *SortedSet[T]) PopAny() (elem T, ok bool) {
+	if len(s.elems) == 0 {
+		return
+	}
+	elem = s.elems[0]
+	s.elems = slices.Delete(s.elems, 0, 1)
+	return elem, true
+}
+
+// Clear removes all the elems from the set
+func (s *SortedSet[T]) Clear() {
+	s.elems = s.elems[:0]
+}
+
+// Min returns the min elem, ok is false when the set is empty
+func (s *SortedSet[T]) Min() (elem T, ok bool) {
+	if len(s.elems) == 0 {
+		return
+	}
+	return s.elems[0], true
+}
+
+// Max returns the max elem, ok is false when the set is empty
+func (s *SortedSet[T]) Max() (elem T, ok bool) {
+	if len(s.elems) == 0 {
+		return
+	}
+	return s.elems[len(s.elems)-1], true
+}
+
+// At returns the elem at the rank i in ascending order, it panics when i is out of range
+func (s *SortedSet[T]) At(i int) T {
+	return s.elems[i]
+}
+
+// Rank returns the number of elems less than elem
+func (s *SortedSet[T]) Rank(elem T) int {
+	i, _ := s.search(elem)
+	return i
+}
+
+// Floor returns the greatest elem less than or equal to elem
+func (s *SortedSet[T]) Floor(elem T) (floor T, ok bool) {
+	i, found := s.search(elem)
+	if found {
+		return s.elems[i], true
+	}
+	if i == 0 {
+		return
+	}
+	return s.elems[i-1], true
+}
+
+// Ceiling returns the least elem greater than or equal to elem
+func (s *SortedSet[T]) Ceiling(elem T) (ceiling T, ok bool) {
+	i, _ := s.search(elem)
+	if i == len(s.elems) {
+		return
+	}
+	return s.elems[i], true
+}
+
+// Range returns the elems in [from, to) in ascending order
+func (s *SortedSet[T]) Range(from, to T) []T {
+	return slices.Collect(s.RangeSeq(from, to))
+}
+
+// RangeSeq returns an iterator over the elems in [from, to) in ascending order
+func (s *SortedSet[T]) RangeSeq(from, to T) iter.Seq[T] {
+	return func(yield func(T) bool) {
+		start, _ := s.search(from)
+		for i := start; i < len(s.elems) && s.compare(s.elems[i], to) < 0; i++ {
+			if !yield(s.elems[i]) {
+				return
+			}
+		}
+	}
+}
+
+// ToSet converts to a plain set
+func (s *SortedSet[T]) ToSet() *Set[T] {
+	return New(s.elems...)
+}
+
+// ToOrderedSet converts to an insertion-ordered set in ascending order
+func (s *SortedSet[T]) ToOrderedSet() *OrderedSet[T] {
+	return NewOrderedSet(s.elems...)
+}
+
+// MarshalJSON encodes the set as a json array in ascending order
+func (s *SortedSet[T]) MarshalJSON() ([]byte, error) {
+	return json.Marshal(s.elems)
+}
+
+// UnmarshalJSON decodes the set from a json array with its compare func,
+// the set should be created by NewSortedSet or NewSortedSetFunc before decoding
+func (s *SortedSet[T]) UnmarshalJSON(data []byte) error {
+	if s.compare == nil {
+		return errors.New("set: decode into a SortedSet not created by NewSortedSet or NewSortedSetFunc")
+	}
+	var elems []T
+	if err := json.Unmarshal(data, &elems); err != nil {
+		return err
+	}
+	*s = *NewSortedSetFunc(s.compare, elems...)
+	return nil
+}
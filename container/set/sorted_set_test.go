@@ -0,0 +1,84 @@
+package set
+
+import (
+	"encoding/json"
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestSortedSet(t *testing.T) {
+	s := NewSortedSet(50, 10, 40, 20, 10)
+	s.Append(30, 60)
+	if !reflect.DeepEqual(s.Elems(), []int{10, 20, 30, 40, 50, 60}) {
+		t.Fatalf("elems should be sorted, got %v", s.Elems())
+	}
+	if !reflect.DeepEqual(s.Range(20, 50), []int{20, 30, 40}) || len(s.Range(61, 70)) != 0 {
+		t.Fatalf("invalid range %v", s.Range(20, 50))
+	}
+	if floor, _ := s.Floor(35); floor != 30 {
+		t.Fatalf("invalid floor %d", floor)
+	}
+	if _, ok := s.Floor(5); ok {
+		t.Fatal("floor of 5 should not exist")
+	}
+	if ceiling, _ := s.Ceiling(35); ceiling != 40 {
+		t.Fatalf("invalid ceiling %d", ceiling)
+	}
+	if _, ok := s.Ceiling(65); ok {
+		t.Fatal("ceiling of 65 should not exist")
+	}
+	if s.Rank(30) != 2 || s.At(2) != 30 {
+		t.Fatal("invalid rank")
+	}
+	if min, _ := s.Min(); min != 10 {
+		t.Fatalf("invalid min %d", min)
+	}
+	if max, _ := s.Max(); max != 60 {
+		t.Fatalf("invalid max %d", max)
+	}
+	if elem, _ := s.PopAny(); elem != 10 {
+		t.Fatalf("pop should return the min elem, got %d", elem)
+	}
+	if !reflect.DeepEqual(slices.Collect(s.Backward())[:2], []int{60, 50}) {
+		t.Fatal("invalid backward iteration")
+	}
+}
+
+func TestSortedSetFunc(t *testing.T) {
+	s := NewSortedSetFunc(func(a, b string) int { return strings.Compare(strings.ToLower(a), strings.ToLower(b)) }, "b", "A", "c", "B")
+	if !reflect.DeepEqual(s.Elems(), []string{"A", "b", "c"}) {
+		t.Fatalf("invalid elems %v", s.Elems())
+	}
+	if !s.Contains("C") {
+		t.Fatal("contains should use the compare func")
+	}
+	ordered := CollectSorted(New(3, 1, 2).All()).ToOrderedSet()
+	if !reflect.DeepEqual(ordered.Elems(), []int{1, 2, 3}) {
+		t.Fatalf("invalid conversion %v", ordered.Elems())
+	}
+}
+
+func TestSortedSetJSON(t *testing.T) {
+	s := NewSortedSet(3, 1, 2)
+	data, _ := json.Marshal(s)
+	decoded := NewSortedSet[int]()
+	if err := json.Unmarshal(data, decoded); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(decoded.Elems(), []int{1, 2, 3}) {
+		t.Fatalf("invalid decoded set %v", decoded.Elems())
+	}
+	if err := json.Unmarshal([]byte(`[2,1,2]`), decoded); err != nil || !reflect.DeepEqual(decoded.Elems(), []int{1, 2}) {
+		t.Fatalf("decoded elems should be sorted and distinct, got %v", decoded.Elems())
+	}
+
+	var zero SortedSet[int]
+	if err := json.Unmarshal(data, &zero); err == nil {
+		t.Fatal("decoding into a zero sorted set should fail")
+	}
+	if zero.Len() != 0 || zero.Contains(1) {
+		t.Fatal("zero sorted set should be empty")
+	}
+}
@@ -0,0 +1,210 @@
+package set
+
+import (
+	"fmt"
+	"hash/maphash"
+	"iter"
+	"sync"
+)
+
+var (
+	_ Interface[int] = (*SyncSet[int])(nil)
+	_ Interface[int] = (*ShardedSet[int])(nil)
+)
+
+// SyncSet is a routine-safe set guarded by a RWMutex
+type SyncSet[T comparable] struct {
+	mu  sync.RWMutex
+	set Set[T]
+}
+
+// NewSyncSet returns a new routine-safe set
+func NewSyncSet[T comparable](elems ...T) *SyncSet[T] {
+	return &SyncSet[T]{set: *New(elems...)}
+}
+
+// Contains returns true when elem in the set
+func (s *SyncSet[T]) Contains(elem T) bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.set.Contains(elem)
+}
+
+// Len returns the number of elems in the set
+func (s *SyncSet[T]) Len() int {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.set.Len()
+}
+
+// Elems returns a snapshot of the elems in random order
+func (s *SyncSet[T]) Elems() []T {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.set.Elems()
+}
+
+// All returns an iterator over a snapshot of the elems, so the set can be modified in the loop
+func (s *SyncSet[T]) All() iter.Seq[T] {
+	return sliceSeq(s.Elems())
+}
+
+// Append appends the elems into the set and return itself
+func (s *SyncSet[T]) Append(elems ...T) *SyncSet[T] {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.set.Append(elems...)
+	return s
+}
+
+// AddIfAbsent adds the elem and returns true when it is not in the set, it is atomic
+func (s *SyncSet[T]) AddIfAbsent(elem T) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.set.AddIfAbsent(elem)
+}
+
+// Delete removes the elem and returns true when it was in the set
+func (s *SyncSet[T]) Delete(elem T) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.set.Delete(elem)
+}
+
+// PopAny removes and returns an arbitrary elem, it is atomic
+func (s *SyncSet[T]) PopAny() (T, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.set.PopAny()
+}
+
+// Clear removes all the elems from the set
+func (s *SyncSet[T]) Clear() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.set.Clear()
+}
+
+// Snapshot returns a plain set copy of the elems
+func (s *SyncSet[T]) Snapshot() *Set[T] {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.set.Clone()
+}
+
+// DefaultShardCount is the default number of shards of ShardedSet
+const DefaultShardCount = 32
+
+var shardSeed = maphash.MakeSeed()
+
+// HashString is the shard hasher for strings
+func HashString(s string) uint64 {
+	return maphash.String(shardSeed, s)
+}
+
+// ShardedSet is a routine-safe set split into shards, each guarded by its own lock,
+// which reduces the contention under heavy writes.
+//
+// Len, Elems and Clear visit the shards one by one, so they are not atomic across shards.
+type ShardedSet[T comparable] struct {
+	shards []*SyncSet[T]
+	hasher func(T) uint64
+}
+
+// NewShardedSet returns a new sharded set, the hasher picks the shard of an elem.
+// When hasher is nil, the elem is formatted by fmt.Sprint and hashed, which is slower.
+func NewShardedSet[T comparable](shardCount int, hasher func(T) uint64) *ShardedSet[T] {
+	if shardCount <= 0 {
+		shardCount = DefaultShardCount
+	}
+	if hasher == nil {
+		hasher = func(elem T) uint64 {
+			return HashString(fmt.Sprint(elem))
+		}
+	}
+	shards := make([]*SyncSet[T], shardCount)
+	for i := range shards {
+		shards[i] = NewSyncSet[T]()
+	}
+	return &ShardedSet[T]{
+		shards: shards,
+		hasher: hasher,
+	}
+}
+
+func (s *ShardedSet[T]) shard(elem T) *SyncSet[T] {
+	return s.shards[s.hasher(elem)%uint64(len(s.shards))]
+}
+
+// Contains returns true when elem in the set
+func (s *ShardedSet[T]) Contains(elem T) bool {
+	return s.shard(elem).Contains(elem)
+}
+
+// Len returns the number of elems in the set
+func (s *ShardedSet[T]) Len() int {
+	size := 0
+	for _, shard := range s.shards {
+		size += shard.Len()
+	}
+	return size
+}
+
+// Elems returns a snapshot of the elems in random order
+func (s *ShardedSet[T]) Elems() []T {
+	elems := make([]T, 0)
+	for _, shard := range s.shards {
+		elems = append(elems, shard.Elems()...)
+	}
+	return elems
+}
+
+// All returns an iterator over a snapshot of the elems, so the set can be modified in the loop
+func (s *ShardedSet[T]) All() iter.Seq[T] {
+	return sliceSeq(s.Elems())
+}
+
+// Append appends the elems into the set and return itself
+func (s *ShardedSet[T]) Append(elems ...T) *ShardedSet[T] {
+	for _, e := range elems {
+		s.shard(e).AddIfAbsent(e)
+	}
+	return s
+}
+
+// AddIfAbsent adds the elem and returns true when it is not in the set, it is atomic
+func (s *ShardedSet[T]) AddIfAbsent(elem T) bool {
+	return s.shard(elem).AddIfAbsent(elem)
+}
+
+// Delete removes the elem and returns true when it was in the set
+func (s *ShardedSet[T]) Delete(elem T) bool {
+	return s.shard(elem).Delete(elem)
+}
+
+// PopAny removes and returns an arbitrary elem, each elem is popped by only one caller
+func (s *ShardedSet[T]) PopAny() (elem T, ok bool) {
+	for _, shard := range s.shards {
+		if elem, ok = shard.PopAny(); ok {
+			return
+		}
+	}
+	return
+}
+
+// Clear removes all the elems from the set
+func (s *ShardedSet[T]) Clear() {
+	for _, shard := range s.shards {
+		shard.Clear()
+	}
+}
+
+func sliceSeq[T any](elems []T) iter.Seq[T] {
+	return func(yield func(T) bool) {
+		for _, e := range elems {
+			if !yield(e) {
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,101 @@
+package set
+
+import (
+	"sync"
+	"sync/atomic"
+	"testing"
+)
+
+func concurrentSets() map[string]Interface[int] {
+	return map[string]Interface[int]{
+		"sync":    NewSyncSet[int](),
+		"sharded": NewShardedSet[int](8, func(i int) uint64 { return uint64(i) }),
+		"default": NewShardedSet[int](0, nil),
+	}
+}
+
+func TestConcurrentAddIfAbsent(t *testing.T) {
+	const workers, elems = 16, 1000
+	for name, s := range concurrentSets() {
+		var added atomic.Int64
+		var wg sync.WaitGroup
+		for w := 0; w < workers; w++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for i := 0; i < elems; i++ {
+					if s.AddIfAbsent(i) {
+						added.Add(1)
+					}
+					s.Contains(i)
+				}
+			}()
+		}
+		wg.Wait()
+		if added.Load() != elems || s.Len() != elems {
+			t.Fatalf("%s set: each elem should be added once, added=%d, len=%d", name, added.Load(), s.Len())
+		}
+	}
+}
+
+func TestConcurrentPopAny(t *testing.T) {
+	const workers, elems = 16, 1000
+	for name, s := range concurrentSets() {
+		for i := 0; i < elems; i++ {
+			s.AddIfAbsent(i)
+		}
+		popped := NewSyncSet[int]()
+		var duplicated atomic.Int64
+		var wg sync.WaitGroup
+		for w := 0; w < workers; w++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for {
+					elem, ok := s.PopAny()
+					if !ok {
+						return
+					}
+					if !popped.AddIfAbsent(elem) {
+						duplicated.Add(1)
+					}
+				}
+			}()
+		}
+		wg.Wait()
+		if duplicated.Load() != 0 || popped.Len() != elems || s.Len() != 0 {
+			t.Fatalf("%s set: each elem should be popped once, duplicated=%d, popped=%d", name, duplicated.Load(), popped.Len())
+		}
+	}
+}
+
+func TestConcurrentMixed(t *testing.T) {
+	for name, s := range concurrentSets() {
+		var wg sync.WaitGroup
+		for w := 0; w < 8; w++ {
+			wg.Add(1)
+			go func(w int) {
+				defer wg.Done()
+				for i := 0; i < 500; i++ {
+					s.AddIfAbsent(w*1000 + i)
+					if i%3 == 0 {
+						s.Delete(w*1000 + i)
+					}
+					if i%100 == 0 {
+						for range s.All() {
+						}
+						s.Elems()
+					}
+				}
+			}(w)
+		}
+		wg.Wait()
+		if s.Len() != 8*(500-167) {
+			t.Fatalf("%s set: unexpected len %d", name, s.Len())
+		}
+		s.Clear()
+		if s.Len() != 0 {
+			t.Fatalf("%s set: should be empty after clear", name)
+		}
+	}
+}
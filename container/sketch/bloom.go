@@ -0,0 +1,183 @@
+package sketch
+
+import (
+	"encoding/binary"
+	"math"
+	"math/bits"
+)
+
+// BloomFilter tests whether an elem is possibly in the set or definitely not, it is not routine-safe
+type BloomFilter struct {
+	m     uint64 // number of bits
+	k     uint32 // number of hash functions
+	n     uint64 // number of added elems
+	words []uint64
+}
+
+// EstimateBloomParams calculates the number of bits and hash functions
+// for n elems with the false positive rate p
+func EstimateBloomParams(n uint64, p float64) (m uint64, k uint32) {
+	if n == 0 {
+		n = 1
+	}
+	if p <= 0 || p >= 1 {
+		p = 0.01
+	}
+	m = uint64(math.Ceil(-float64(n) * math.Log(p) / (math.Ln2 * math.Ln2)))
+	k = uint32(math.Max(1, math.Round(float64(m)/float64(n)*math.Ln2)))
+	return
+}
+
+// EstimateFalsePositiveRate calculates the false positive rate of m bits and k hash functions with n elems
+func EstimateFalsePositiveRate(m uint64, k uint32, n uint64) float64 {
+	return math.Pow(1-math.Exp(-float64(k)*float64(n)/float64(m)), float64(k))
+}
+
+// NewBloomFilter creates a bloom filter with m bits and k hash functions
+func NewBloomFilter(m uint64, k uint32) *BloomFilter {
+	if m == 0 {
+		m = 1
+	}
+	if k == 0 {
+		k = 1
+	}
+	return &BloomFilter{
+		m:     m,
+		k:     k,
+		words: make([]uint64, (m+63)/64),
+	}
+}
+
+// NewBloomFilterWithEstimates creates a bloom filter for n elems with the false positive rate p
+func NewBloomFilterWithEstimates(n uint64, p float64) *BloomFilter {
+	return NewBloomFilter(EstimateBloomParams(n, p))
+}
+
+// Cap returns the number of bits
+func (f *BloomFilter) Cap() uint64 {
+	return f.m
+}
+
+// K returns the number of hash functions
+func (f *BloomFilter) K() uint32 {
+	return f.k
+}
+
+// Count returns the number of added elems, duplicated elems are counted repeatedly
+func (f *BloomFilter) Count() uint64 {
+	return f.n
+}
+
+// EstimatedCount estimates the number of distinct elems by the set bits, which also works after merging
+func (f *BloomFilter) EstimatedCount() uint64 {
+	setBits := 0
+	for _, w := range f.words {
+		setBits += bits.OnesCount64(w)
+	}
+	if uint64(setBits) >= f.m {
+		return f.n
+	}
+	return uint64(math.Round(-float64(f.m) / float64(f.k) * math.Log(1-float64(setBits)/float64(f.m))))
+}
+
+// locations returns the bit locations by double hashing
+func (f *BloomFilter) locations(data []byte, fn func(loc uint64) bool) bool {
+	h1 := hash64(data)
+	h2 := mix64(h1) | 1
+	for i := uint64(0); i < uint64(f.k); i++ {
+		if !fn((h1 + i*h2) % f.m) {
+			return false
+		}
+	}
+	return true
+}
+
+// Add adds the elem into the filter
+func (f *BloomFilter) Add(data []byte) {
+	f.locations(data, func(loc uint64) bool {
+		f.words[loc/64] |= 1 << (loc % 64)
+		return true
+	})
+	f.n++
+}
+
+// AddString adds the string elem into the filter
+func (f *BloomFilter) AddString(s string) {
+	f.Add([]byte(s))
+}
+
+// Test returns false when the elem is definitely not in the filter
+func (f *BloomFilter) Test(data []byte) bool {
+	return f.locations(data, func(loc uint64) bool {
+		return f.words[loc/64]&(1<<(loc%64)) != 0
+	})
+}
+
+// TestString returns false when the string elem is definitely not in the filter
+func (f *BloomFilter) TestString(s string) bool {
+	return f.Test([]byte(s))
+}
+
+// TestAndAdd tests the elem and adds it, which is useful for dedupe
+func (f *BloomFilter) TestAndAdd(data []byte) bool {
+	present := f.Test(data)
+	f.Add(data)
+	return present
+}
+
+// FalsePositiveRate estimates the current false positive rate
+func (f *BloomFilter) FalsePositiveRate() float64 {
+	return EstimateFalsePositiveRate(f.m, f.k, f.EstimatedCount())
+}
+
+// Merge unions the other filter with the same parameters into f
+func (f *BloomFilter) Merge(other *BloomFilter) error {
+	if f.m != other.m || f.k != other.k {
+		return ErrIncompatible
+	}
+	for i, w := range other.words {
+		f.words[i] |= w
+	}
+	f.n += other.n
+	return nil
+}
+
+// Clear resets the filter
+func (f *BloomFilter) Clear() {
+	clear(f.words)
+	f.n = 0
+}
+
+// MarshalBinary implements encoding.BinaryMarshaler
+func (f *BloomFilter) MarshalBinary() ([]byte, error) {
+	data := make([]byte, 0, 2+4+8+8+len(f.words)*8)
+	data = append(data, bloomMagic, formatVersion)
+	data = binary.BigEndian.AppendUint32(data, f.k)
+	data = binary.BigEndian.AppendUint64(data, f.m)
+	data = binary.BigEndian.AppendUint64(data, f.n)
+	for _, w := range f.words {
+		data = binary.BigEndian.AppendUint64(data, w)
+	}
+	return data, nil
+}
+
+// UnmarshalBinary implements encoding.BinaryUnmarshaler
+func (f *BloomFilter) UnmarshalBinary(data []byte) error {
+	if len(data) < 22 || data[0] != bloomMagic || data[1] != formatVersion {
+		return ErrInvalidData
+	}
+	k := binary.BigEndian.Uint32(data[2:6])
+	m := binary.BigEndian.Uint64(data[6:14])
+	n := binary.BigEndian.Uint64(data[14:22])
+	body := data[22:]
+	if k == 0 || k > maxBloomHashes || m == 0 || m > maxBloomBits ||
+		len(body)%8 != 0 || uint64(len(body))/8 != (m-1)/64+1 {
+		return ErrInvalidData
+	}
+	words := make([]uint64, len(body)/8)
+	for i := range words {
+		words[i] = binary.BigEndian.Uint64(body[i*8:])
+	}
+	*f = BloomFilter{m: m, k: k, n: n, words: words}
+	return nil
+}
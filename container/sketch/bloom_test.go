@@ -0,0 +1,70 @@
+package sketch
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"testing"
+)
+
+func TestEstimateBloomParams(t *testing.T) {
+	m, k := EstimateBloomParams(1000000, 0.01)
+	if m != 9585059 || k != 7 {
+		t.Fatalf("invalid params m=%d, k=%d", m, k)
+	}
+	if rate := EstimateFalsePositiveRate(m, k, 1000000); math.Abs(rate-0.01) > 0.001 {
+		t.Fatalf("invalid false positive rate %f", rate)
+	}
+}
+
+func TestBloomFilter(t *testing.T) {
+	f := NewBloomFilterWithEstimates(10000, 0.01)
+	for i := 0; i < 10000; i++ {
+		f.AddString(fmt.Sprintf("req-%d", i))
+	}
+	for i := 0; i < 10000; i++ {
+		if !f.TestString(fmt.Sprintf("req-%d", i)) {
+			t.Fatalf("added elem req-%d should be present", i)
+		}
+	}
+	falsePositives := 0
+	for i := 10000; i < 20000; i++ {
+		if f.TestString(fmt.Sprintf("req-%d", i)) {
+			falsePositives++
+		}
+	}
+	if rate := float64(falsePositives) / 10000; rate > 0.02 {
+		t.Fatalf("false positive rate %f is too high", rate)
+	}
+	if estimated := f.EstimatedCount(); estimated < 9500 || estimated > 10500 {
+		t.Fatalf("invalid estimated count %d", estimated)
+	}
+}
+
+func TestBloomFilterMergeAndBinary(t *testing.T) {
+	a := NewBloomFilter(1024, 3)
+	b := NewBloomFilter(1024, 3)
+	a.AddString("node-a")
+	b.AddString("node-b")
+	if err := a.Merge(b); err != nil {
+		t.Fatal(err)
+	}
+	if !a.TestString("node-a") || !a.TestString("node-b") || a.Count() != 2 {
+		t.Fatal("merged filter should hold both elems")
+	}
+	if err := a.Merge(NewBloomFilter(2048, 3)); !errors.Is(err, ErrIncompatible) {
+		t.Fatalf("expect ErrIncompatible, got %v", err)
+	}
+
+	data, _ := a.MarshalBinary()
+	var decoded BloomFilter
+	if err := decoded.UnmarshalBinary(data); err != nil {
+		t.Fatal(err)
+	}
+	if !decoded.TestString("node-a") || decoded.Cap() != 1024 || decoded.K() != 3 || decoded.Count() != 2 {
+		t.Fatal("decoded filter should equal the origin")
+	}
+	if err := decoded.UnmarshalBinary(data[:len(data)-1]); !errors.Is(err, ErrInvalidData) {
+		t.Fatalf("expect ErrInvalidData, got %v", err)
+	}
+}
@@ -0,0 +1,228 @@
+package sketch
+
+import (
+	"encoding/binary"
+	"math/bits"
+	"math/rand/v2"
+)
+
+const (
+	cuckooBucketSize = 4
+	cuckooMaxKicks   = 500
+)
+
+type cuckooBucket [cuckooBucketSize]uint16
+
+// CuckooFilter is a membership filter which supports deletion, it is not routine-safe.
+//
+// Each elem is stored as a 16-bit fingerprint in one of its two candidate buckets,
+// so only the elems that were added should be deleted, or other elems may be lost.
+type CuckooFilter struct {
+	buckets []cuckooBucket
+	mask    uint64
+	count   uint64
+}
+
+// NewCuckooFilter creates a cuckoo filter for about capacity elems
+func NewCuckooFilter(capacity uint64) *CuckooFilter {
+	// keep the load factor under 95%
+	numBuckets := uint64(1)
+	if need := (capacity*100/95 + cuckooBucketSize - 1) / cuckooBucketSize; need > 1 {
+		numBuckets = 1 << bits.Len64(need-1)
+	}
+	return &CuckooFilter{
+		buckets: make([]cuckooBucket, numBuckets),
+		mask:    numBuckets - 1,
+	}
+}
+
+// Cap returns the number of fingerprint slots
+func (f *CuckooFilter) Cap() uint64 {
+	return uint64(len(f.buckets)) * cuckooBucketSize
+}
+
+// Count returns the number of elems in the filter
+func (f *CuckooFilter) Count() uint64 {
+	return f.count
+}
+
+// LoadFactor returns the ratio of used slots
+func (f *CuckooFilter) LoadFactor() float64 {
+	return float64(f.count) / float64(f.Cap())
+}
+
+func (f *CuckooFilter) indexes(data []byte) (fp uint16, i1, i2 uint64) {
+	h := hash64(data)
+	fp = uint16(h >> 48)
+	if fp == 0 {
+		fp = 1
+	}
+	i1 = h & f.mask
+	i2 = f.altIndex(i1, fp)
+	return
+}
+
+func (f *CuckooFilter) altIndex(i uint64, fp uint16) uint64 {
+	return (i ^ mix64(uint64(fp))) & f.mask
+}
+
+func (b *cuckooBucket) insert(fp uint16) bool {
+	for i, v := range b {
+		if v == 0 {
+			b[i] = fp
+			return true
+		}
+	}
+	return false
+}
+
+func (b *cuckooBucket) remove(fp uint16) bool {
+	for i, v := range b {
+		if v == fp {
+			b[i] = 0
+			return true
+		}
+	}
+	return false
+}
+
+func (b *cuckooBucket) contains(fp uint16) bool {
+	for _, v := range b {
+		if v == fp {
+			return true
+		}
+	}
+	return false
+}
+
+// Add adds the elem and returns false when the filter is too full
+func (f *CuckooFilter) Add(data []byte) bool {
+	fp, i1, i2 := f.indexes(data)
+	return f.insert(fp, i1, i2)
+}
+
+// AddString adds the string elem and returns false when the filter is too full
+func (f *CuckooFilter) AddString(s string) bool {
+	return f.Add([]byte(s))
+}
+
+func (f *CuckooFilter) insert(fp uint16, i1, i2 uint64) bool {
+	if f.buckets[i1].insert(fp) || f.buckets[i2].insert(fp) {
+		f.count++
+		return true
+	}
+	// relocate the existing fingerprints, roll back on failure so no elem is lost
+	type kick struct {
+		bucket uint64
+		slot   int
+		fp     uint16
+	}
+	kicks := make([]kick, 0, cuckooMaxKicks)
+	i := i1
+	if rand.IntN(2) == 1 {
+		i = i2
+	}
+	for n := 0; n < cuckooMaxKicks; n++ {
+		slot := rand.IntN(cuckooBucketSize)
+		kicks = append(kicks, kick{bucket: i, slot: slot, fp: f.buckets[i][slot]})
+		fp, f.buckets[i][slot] = f.buckets[i][slot], fp
+		i = f.altIndex(i, fp)
+		if f.buckets[i].insert(fp) {
+			f.count++
+			return true
+		}
+	}
+	for n := len(kicks) - 1; n >= 0; n-- {
+		f.buckets[kicks[n].bucket][kicks[n].slot] = kicks[n].fp
+	}
+	return false
+}
+
+// Test returns false when the elem is definitely not in the filter
+func (f *CuckooFilter) Test(data []byte) bool {
+	fp, i1, i2 := f.indexes(data)
+	return f.buckets[i1].contains(fp) || f.buckets[i2].contains(fp)
+}
+
+// TestString returns false when the string elem is definitely not in the filter
+func (f *CuckooFilter) TestString(s string) bool {
+	return f.Test([]byte(s))
+}
+
+// Delete removes one occurrence of the elem and returns true when it was found
+func (f *CuckooFilter) Delete(data []byte) bool {
+	fp, i1, i2 := f.indexes(data)
+	if f.buckets[i1].remove(fp) || f.buckets[i2].remove(fp) {
+		f.count--
+		return true
+	}
+	return false
+}
+
+// DeleteString removes one occurrence of the string elem and returns true when it was found
+func (f *CuckooFilter) DeleteString(s string) bool {
+	return f.Delete([]byte(s))
+}
+
+// Merge adds all the elems of the other filter with the same capacity into f,
+// it returns false when f is too full, and the elems merged so far are kept.
+func (f *CuckooFilter) Merge(other *CuckooFilter) (bool, error) {
+	if len(f.buckets) != len(other.buckets) {
+		return false, ErrIncompatible
+	}
+	for i, bucket := range other.buckets {
+		for _, fp := range bucket {
+			if fp == 0 {
+				continue
+			}
+			if !f.insert(fp, uint64(i), f.altIndex(uint64(i), fp)) {
+				return false, nil
+			}
+		}
+	}
+	return true, nil
+}
+
+// Clear resets the filter
+func (f *CuckooFilter) Clear() {
+	clear(f.buckets)
+	f.count = 0
+}
+
+// MarshalBinary implements encoding.BinaryMarshaler
+func (f *CuckooFilter) MarshalBinary() ([]byte, error) {
+	data := make([]byte, 0, 2+8+8+len(f.buckets)*cuckooBucketSize*2)
+	data = append(data, cuckooMagic, formatVersion)
+	data = binary.BigEndian.AppendUint64(data, uint64(len(f.buckets)))
+	data = binary.BigEndian.AppendUint64(data, f.count)
+	for _, bucket := range f.buckets {
+		for _, fp := range bucket {
+			data = binary.BigEndian.AppendUint16(data, fp)
+		}
+	}
+	return data, nil
+}
+
+// UnmarshalBinary implements encoding.BinaryUnmarshaler
+func (f *CuckooFilter) UnmarshalBinary(data []byte) error {
+	if len(data) < 18 || data[0] != cuckooMagic || data[1] != formatVersion {
+		return ErrInvalidData
+	}
+	numBuckets := binary.BigEndian.Uint64(data[2:10])
+	count := binary.BigEndian.Uint64(data[10:18])
+	body := data[18:]
+	if len(body)%(cuckooBucketSize*2) != 0 || numBuckets != uint64(len(body)/(cuckooBucketSize*2)) {
+		return ErrInvalidData
+	}
+	if numBuckets == 0 || numBuckets > maxCuckooBuckets || numBuckets&(numBuckets-1) != 0 || count > numBuckets*cuckooBucketSize {
+		return ErrInvalidData
+	}
+	buckets := make([]cuckooBucket, numBuckets)
+	for i := range buckets {
+		for j := range buckets[i] {
+			buckets[i][j] = binary.BigEndian.Uint16(body[(i*cuckooBucketSize+j)*2:])
+		}
+	}
+	*f = CuckooFilter{buckets: buckets, mask: numBuckets - 1, count: count}
+	return nil
+}
@@ -0,0 +1,95 @@
+package sketch
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+)
+
+func TestCuckooFilter(t *testing.T) {
+	f := NewCuckooFilter(10000)
+	for i := 0; i < 10000; i++ {
+		if !f.AddString(fmt.Sprintf("user-%d", i)) {
+			t.Fatalf("filter should not be full at %d, load factor %f", i, f.LoadFactor())
+		}
+	}
+	for i := 0; i < 10000; i++ {
+		if !f.TestString(fmt.Sprintf("user-%d", i)) {
+			t.Fatalf("added elem user-%d should be present", i)
+		}
+	}
+	falsePositives := 0
+	for i := 10000; i < 20000; i++ {
+		if f.TestString(fmt.Sprintf("user-%d", i)) {
+			falsePositives++
+		}
+	}
+	if rate := float64(falsePositives) / 10000; rate > 0.01 {
+		t.Fatalf("false positive rate %f is too high", rate)
+	}
+
+	for i := 0; i < 5000; i++ {
+		if !f.DeleteString(fmt.Sprintf("user-%d", i)) {
+			t.Fatalf("added elem user-%d should be deleted", i)
+		}
+	}
+	if f.Count() != 5000 {
+		t.Fatalf("invalid count %d", f.Count())
+	}
+	for i := 5000; i < 10000; i++ {
+		if !f.TestString(fmt.Sprintf("user-%d", i)) {
+			t.Fatalf("elem user-%d should survive the deletion of others", i)
+		}
+	}
+}
+
+func TestCuckooFilterFull(t *testing.T) {
+	f := NewCuckooFilter(8)
+	added := 0
+	for i := 0; i < 100; i++ {
+		if f.AddString(fmt.Sprintf("elem-%d", i)) {
+			added++
+		}
+	}
+	if uint64(added) != f.Count() || f.Count() > f.Cap() {
+		t.Fatalf("invalid count %d of cap %d", f.Count(), f.Cap())
+	}
+	// a failed insertion should not lose the existing elems
+	for i := 0; i < 100; i++ {
+		elem := fmt.Sprintf("elem-%d", i)
+		if f.TestString(elem) {
+			f.DeleteString(elem)
+		}
+	}
+	if f.Count() != 0 {
+		t.Fatalf("all elems should be found and deleted, %d left", f.Count())
+	}
+}
+
+func TestCuckooFilterMergeAndBinary(t *testing.T) {
+	a := NewCuckooFilter(100)
+	b := NewCuckooFilter(100)
+	a.AddString("node-a")
+	b.AddString("node-b")
+	if ok, err := a.Merge(b); !ok || err != nil {
+		t.Fatalf("merge failed, %v", err)
+	}
+	if !a.TestString("node-a") || !a.TestString("node-b") || a.Count() != 2 {
+		t.Fatal("merged filter should hold both elems")
+	}
+	if _, err := a.Merge(NewCuckooFilter(1000)); !errors.Is(err, ErrIncompatible) {
+		t.Fatalf("expect ErrIncompatible, got %v", err)
+	}
+
+	data, _ := a.MarshalBinary()
+	var decoded CuckooFilter
+	if err := decoded.UnmarshalBinary(data); err != nil {
+		t.Fatal(err)
+	}
+	if !decoded.DeleteString("node-b") || decoded.TestString("node-b") || decoded.Count() != 1 {
+		t.Fatal("decoded filter should equal the origin")
+	}
+	if err := decoded.UnmarshalBinary(data[:10]); !errors.Is(err, ErrInvalidData) {
+		t.Fatalf("expect ErrInvalidData, got %v", err)
+	}
+}
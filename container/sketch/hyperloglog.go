@@ -0,0 +1,128 @@
+package sketch
+
+import (
+	"fmt"
+	"math"
+	"math/bits"
+)
+
+const (
+	// MinPrecision is the min precision of HyperLogLog
+	MinPrecision = 4
+	// MaxPrecision is the max precision of HyperLogLog
+	MaxPrecision = 18
+)
+
+// HyperLogLog estimates the number of distinct elems, it is not routine-safe.
+// The standard error is about 1.04/sqrt(2^precision), such as 0.81% with precision 14.
+type HyperLogLog struct {
+	p         uint8
+	registers []uint8
+}
+
+// NewHyperLogLog creates a HyperLogLog with 2^precision registers
+func NewHyperLogLog(precision uint8) (*HyperLogLog, error) {
+	if precision < MinPrecision || precision > MaxPrecision {
+		return nil, fmt.Errorf("sketch: precision should be in [%d, %d]", MinPrecision, MaxPrecision)
+	}
+	return &HyperLogLog{
+		p:         precision,
+		registers: make([]uint8, 1<<precision),
+	}, nil
+}
+
+// Precision returns the precision
+func (h *HyperLogLog) Precision() uint8 {
+	return h.p
+}
+
+// Add adds the elem
+func (h *HyperLogLog) Add(data []byte) {
+	x := hash64(data)
+	idx := x >> (64 - h.p)
+	// the sentinel bit limits the rank to 64-p+1
+	rank := uint8(bits.LeadingZeros64(x<<h.p|1<<(h.p-1)) + 1)
+	if rank > h.registers[idx] {
+		h.registers[idx] = rank
+	}
+}
+
+// AddString adds the string elem
+func (h *HyperLogLog) AddString(s string) {
+	h.Add([]byte(s))
+}
+
+// Count estimates the number of distinct elems
+func (h *HyperLogLog) Count() uint64 {
+	m := float64(len(h.registers))
+	var sum float64
+	zeros := 0
+	for _, r := range h.registers {
+		sum += 1 / float64(uint64(1)<<r)
+		if r == 0 {
+			zeros++
+		}
+	}
+	estimate := h.alpha() * m * m / sum
+	// use linear counting for the small range
+	if estimate <= 2.5*m && zeros > 0 {
+		estimate = m * math.Log(m/float64(zeros))
+	}
+	return uint64(math.Round(estimate))
+}
+
+func (h *HyperLogLog) alpha() float64 {
+	switch m := float64(len(h.registers)); m {
+	case 16:
+		return 0.673
+	case 32:
+		return 0.697
+	case 64:
+		return 0.709
+	default:
+		return 0.7213 / (1 + 1.079/m)
+	}
+}
+
+// Merge unions the other HyperLogLog with the same precision into h
+func (h *HyperLogLog) Merge(other *HyperLogLog) error {
+	if h.p != other.p {
+		return ErrIncompatible
+	}
+	for i, r := range other.registers {
+		if r > h.registers[i] {
+			h.registers[i] = r
+		}
+	}
+	return nil
+}
+
+// Clear resets the HyperLogLog
+func (h *HyperLogLog) Clear() {
+	clear(h.registers)
+}
+
+// MarshalBinary implements encoding.BinaryMarshaler
+func (h *HyperLogLog) MarshalBinary() ([]byte, error) {
+	data := make([]byte, 0, 3+len(h.registers))
+	data = append(data, hyperLogLogMagic, formatVersion, h.p)
+	return append(data, h.registers...), nil
+}
+
+// UnmarshalBinary implements encoding.BinaryUnmarshaler
+func (h *HyperLogLog) UnmarshalBinary(data []byte) error {
+	if len(data) < 3 || data[0] != hyperLogLogMagic || data[1] != formatVersion {
+		return ErrInvalidData
+	}
+	p := data[2]
+	if p < MinPrecision || p > MaxPrecision || len(data)-3 != 1<<p {
+		return ErrInvalidData
+	}
+	for _, r := range data[3:] {
+		if r > 64-p+1 {
+			return ErrInvalidData
+		}
+	}
+	*h = HyperLogLog{p: p, registers: append([]uint8(nil), data[3:]...)}
+	return nil
+}
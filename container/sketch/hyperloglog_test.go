@@ -0,0 +1,55 @@
+package sketch
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"testing"
+)
+
+func TestHyperLogLog(t *testing.T) {
+	if _, err := NewHyperLogLog(3); err == nil {
+		t.Fatal("precision 3 should be rejected")
+	}
+	for _, n := range []int{10, 1000, 100000} {
+		h, _ := NewHyperLogLog(14)
+		for i := 0; i < n; i++ {
+			h.AddString(fmt.Sprintf("user-%d", i))
+			h.AddString(fmt.Sprintf("user-%d", i))
+		}
+		if diff := math.Abs(float64(h.Count())-float64(n)) / float64(n); diff > 0.03 {
+			t.Fatalf("estimate %d is too far from %d", h.Count(), n)
+		}
+	}
+}
+
+func TestHyperLogLogMergeAndBinary(t *testing.T) {
+	a, _ := NewHyperLogLog(12)
+	b, _ := NewHyperLogLog(12)
+	for i := 0; i < 5000; i++ {
+		a.AddString(fmt.Sprintf("user-%d", i))
+		b.AddString(fmt.Sprintf("user-%d", i+2500))
+	}
+	if err := a.Merge(b); err != nil {
+		t.Fatal(err)
+	}
+	if diff := math.Abs(float64(a.Count())-7500) / 7500; diff > 0.05 {
+		t.Fatalf("merged estimate %d is too far from 7500", a.Count())
+	}
+	other, _ := NewHyperLogLog(10)
+	if err := a.Merge(other); !errors.Is(err, ErrIncompatible) {
+		t.Fatalf("expect ErrIncompatible, got %v", err)
+	}
+
+	data, _ := a.MarshalBinary()
+	var decoded HyperLogLog
+	if err := decoded.UnmarshalBinary(data); err != nil {
+		t.Fatal(err)
+	}
+	if decoded.Count() != a.Count() || decoded.Precision() != 12 {
+		t.Fatal("decoded HyperLogLog should equal the origin")
+	}
+	if err := decoded.UnmarshalBinary(data[:100]); !errors.Is(err, ErrInvalidData) {
+		t.Fatalf("expect ErrInvalidData, got %v", err)
+	}
+}
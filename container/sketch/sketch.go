@@ -0,0 +1,48 @@
+// Package sketch provides probabilistic set structures, which answer membership and
+// cardinality questions approximately with a small fixed memory.
+// All of them can be serialized to bytes and merged across nodes.
+package sketch
+
+import (
+	"errors"
+
+	"github.com/cespare/xxhash/v2"
+)
+
+var (
+	// ErrIncompatible is returned when merging structures with different parameters
+	ErrIncompatible = errors.New("sketch: incompatible parameters")
+
+	// ErrInvalidData is returned when the serialized bytes can not be decoded
+	ErrInvalidData = errors.New("sketch: invalid data")
+)
+
+// binary format versions
+const (
+	bloomMagic       byte = 'B'
+	cuckooMagic      byte = 'C'
+	hyperLogLogMagic byte = 'H'
+	formatVersion    byte = 1
+)
+
+// the max sizes accepted when decoding, which keep the corrupt data from allocating huge filters
+const (
+	maxBloomBits     = 1 << 40
+	maxBloomHashes   = 64
+	maxCuckooBuckets = 1 << 32
+)
+
+// hash64 returns the 64-bit hash of the data
+func hash64(data []byte) uint64 {
+	return xxhash.Sum64(data)
+}
+
+// mix64 is the finalizer of splitmix64, which derives an independent hash from h
+func mix64(h uint64) uint64 {
+	h ^= h >> 30
+	h *= 0xbf58476d1ce4e5b9
+	h ^= h >> 27
+	h *= 0x94d049bb133111eb
+	h ^= h >> 31
+	return h
+}
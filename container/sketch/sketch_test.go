@@ -0,0 +1,53 @@
+package sketch
+
+import (
+	"encoding"
+	"encoding/binary"
+	"testing"
+)
+
+func TestUnmarshalOverflow(t *testing.T) {
+	bloom := []byte{bloomMagic, formatVersion}
+	bloom = binary.BigEndian.AppendUint32(bloom, 3)
+	bloom = binary.BigEndian.AppendUint64(bloom, 1<<64-1)
+	bloom = binary.BigEndian.AppendUint64(bloom, 0)
+	if err := new(BloomFilter).UnmarshalBinary(bloom); err != ErrInvalidData {
+		t.Fatalf("overflowed bloom size should be rejected, got %v", err)
+	}
+
+	cuckoo := []byte{cuckooMagic, formatVersion}
+	cuckoo = binary.BigEndian.AppendUint64(cuckoo, 1<<61)
+	cuckoo = binary.BigEndian.AppendUint64(cuckoo, 0)
+	if err := new(CuckooFilter).UnmarshalBinary(cuckoo); err != ErrInvalidData {
+		t.Fatalf("overflowed cuckoo size should be rejected, got %v", err)
+	}
+}
+
+func FuzzUnmarshalBinary(f *testing.F) {
+	bloom := NewBloomFilter(128, 3)
+	bloom.Add([]byte("a"))
+	cuckoo := NewCuckooFilter(16)
+	cuckoo.Add([]byte("a"))
+	hll, _ := NewHyperLogLog(MinPrecision)
+	hll.Add([]byte("a"))
+	for _, m := range []encoding.BinaryMarshaler{bloom, cuckoo, hll} {
+		data, _ := m.MarshalBinary()
+		f.Add(data)
+	}
+	f.Fuzz(func(t *testing.T, data []byte) {
+		// the decoded filters should never panic when used
+		if b := new(BloomFilter); b.UnmarshalBinary(data) == nil {
+			b.Test([]byte("a"))
+			b.Add([]byte("b"))
+		}
+		if c := new(CuckooFilter); c.UnmarshalBinary(data) == nil {
+			c.Test([]byte("a"))
+			c.Add([]byte("b"))
+			c.Delete([]byte("a"))
+		}
+		if h := new(HyperLogLog); h.UnmarshalBinary(data) == nil {
+			h.Add([]byte("a"))
+			h.Count()
+		}
+	})
+}
@@ -0,0 +1,113 @@
+package tree
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"errors"
+	"io"
+	"io/fs"
+	"strings"
+)
+
+// archiveEntry is an entry listed in an archive
+type archiveEntry struct {
+	name string
+	dir  bool
+	info FileInfo
+}
+
+// BuildTreeZip builds the tree of the zip listing, IgnoreFile and Symlinks in opts are not used
+func BuildTreeZip(r *zip.Reader, opts *BuildOptions) (*Tree[FileInfo], error) {
+	b, err := newBuilder(opts)
+	if err != nil {
+		return nil, err
+	}
+	for _, f := range r.File {
+		entry := archiveEntry{
+			name: f.Name,
+			dir:  strings.HasSuffix(f.Name, "/"),
+			info: FileInfo{Size: int64(f.UncompressedSize64), ModTime: f.Modified, Mode: f.Mode()},
+		}
+		if err = b.addEntry(entry); err != nil {
+			return nil, err
+		}
+	}
+	return b.finish(), nil
+}
+
+// BuildTreeTar builds the tree of the tar listing, only the headers are read and the contents are skipped.
+// IgnoreFile and Symlinks in opts are not used, the links are leaves.
+func BuildTreeTar(r io.Reader, opts *BuildOptions) (*Tree[FileInfo], error) {
+	b, err := newBuilder(opts)
+	if err != nil {
+		return nil, err
+	}
+	tr := tar.NewReader(r)
+	for {
+		hdr, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		switch hdr.Typeflag {
+		case tar.TypeReg, tar.TypeDir, tar.TypeSymlink, tar.TypeLink:
+		default:
+			continue
+		}
+		entry := archiveEntry{
+			name: hdr.Name,
+			dir:  hdr.Typeflag == tar.TypeDir,
+			info: FileInfo{Size: hdr.Size, ModTime: hdr.ModTime, Mode: hdr.FileInfo().Mode()},
+		}
+		if err = b.addEntry(entry); err != nil {
+			return nil, err
+		}
+	}
+	return b.finish(), nil
+}
+
+// FromZip creates the file tree of the zip listing, the nodes carry their metadata in Info
+func FromZip(r *zip.Reader, opts *BuildOptions) (FileTreeNodes, error) {
+	t, err := BuildTreeZip(r, opts)
+	if err != nil {
+		return nil, err
+	}
+	return ToFileTreeNodesWithInfo(t), nil
+}
+
+// FromTar creates the file tree of the tar listing, the nodes carry their metadata in Info
+func FromTar(r io.Reader, opts *BuildOptions) (FileTreeNodes, error) {
+	t, err := BuildTreeTar(r, opts)
+	if err != nil {
+		return nil, err
+	}
+	return ToFileTreeNodesWithInfo(t), nil
+}
+
+func (b *builder) addEntry(entry archiveEntry) error {
+	names := SplitPath(entry.name)
+	if len(names) == 0 {
+		return nil
+	}
+	p := strings.Join(names, "/")
+	if b.ignore.MatchAll(p, entry.dir) {
+		return nil
+	}
+	if b.opts.MaxDepth > 0 && len(names) > b.opts.MaxDepth {
+		// keep the ancestor directory within the depth
+		_, err := b.tree.mkdirAll(names[:b.opts.MaxDepth])
+		return err
+	}
+	if entry.dir {
+		entry.info.Mode |= fs.ModeDir
+		_, err := b.tree.InsertDir(p, entry.info)
+		return err
+	}
+	if !b.keepFile(p) {
+		return nil
+	}
+	_, err := b.tree.Insert(p, entry.info)
+	return err
+}
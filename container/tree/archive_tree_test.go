@@ -0,0 +1,75 @@
+package tree
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"slices"
+	"testing"
+)
+
+func TestBuildTreeZip(t *testing.T) {
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	for name, data := range map[string]string{
+		"app/":             "",
+		"app/main.go":      "package main",
+		"app/conf/a.yaml":  "a: 1",
+		"app/conf/b.log":   "log",
+		"README.md":        "# readme",
+		"app/deep/x/y/z.c": "int",
+	} {
+		w, err := zw.CreateHeader(&zip.FileHeader{Name: name, Modified: testModTime})
+		if err != nil {
+			t.Fatal(err)
+		}
+		w.Write([]byte(data))
+	}
+	zw.Close()
+	zr, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tr, err := BuildTreeZip(zr, &BuildOptions{Exclude: []string{"*.log"}, MaxDepth: 3})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"app", "app/conf", "app/conf/a.yaml", "app/deep", "app/deep/x", "app/main.go", "README.md"}
+	if got := nodeKeys(ToFileTreeNodesWithInfo(tr)); !slices.Equal(got, want) {
+		t.Fatalf("unexpected keys %v", got)
+	}
+	conf, _ := tr.Find("app/conf")
+	if conf.Data.Size != 4 || !conf.Data.ModTime.Equal(testModTime) {
+		t.Fatalf("implied directory should aggregate the children, got %+v", conf.Data)
+	}
+}
+
+func TestBuildTreeTar(t *testing.T) {
+	var buf bytes.Buffer
+	tw := tar.NewWriter(&buf)
+	entries := []*tar.Header{
+		{Name: "src/a.go", Typeflag: tar.TypeReg, Size: 3, Mode: 0644, ModTime: testModTime},
+		{Name: "src/", Typeflag: tar.TypeDir, Mode: 0755, ModTime: testModTime},
+		{Name: "src/link", Typeflag: tar.TypeSymlink, Linkname: "a.go", Mode: 0777},
+		{Name: "dev/null", Typeflag: tar.TypeChar},
+		{Name: "docs/b.md", Typeflag: tar.TypeReg, Size: 5, Mode: 0644},
+	}
+	for _, hdr := range entries {
+		tw.WriteHeader(hdr)
+		tw.Write(make([]byte, hdr.Size))
+	}
+	tw.Close()
+
+	nodes, err := FromTar(&buf, &BuildOptions{Include: []string{"*.go", "link"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := nodeKeys(nodes); !slices.Equal(got, []string{"src", "src/a.go", "src/link"}) {
+		t.Fatalf("unexpected keys %v", got)
+	}
+	src := nodes[0].Info
+	if src.Size != 3 || src.Mode.Perm() != 0755 || !src.Mode.IsDir() {
+		t.Fatalf("unexpected src info %+v", src)
+	}
+}
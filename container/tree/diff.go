@@ -0,0 +1,250 @@
+package tree
+
+import (
+	"fmt"
+	"io"
+	"path"
+	"slices"
+	"strings"
+)
+
+// DiffStatus is the change status of a node between two file trees
+type DiffStatus string
+
+const (
+	StatusUnchanged DiffStatus = ""
+	StatusAdded     DiffStatus = "added"
+	StatusRemoved   DiffStatus = "removed"
+	StatusModified  DiffStatus = "modified" // the content changed, or something inside changed for a directory
+	StatusRenamed   DiffStatus = "renamed"  // moved from OldKey with the same content hash
+)
+
+// DiffOptions is the options of DiffFileTrees
+type DiffOptions struct {
+	OldHashes     map[string]string // content hashes of the old files by key
+	NewHashes     map[string]string // content hashes of the new files by key
+	DetectRenames bool              // pair the removed and added files with the same hash as renamed
+	OnlyChanges   bool              // drop the unchanged nodes
+}
+
+// DiffEntry is a changed file
+type DiffEntry struct {
+	Status DiffStatus `json:"status"`
+	Key    string     `json:"key"`
+	OldKey string     `json:"oldKey,omitempty"` // set when renamed
+}
+
+type diffData struct {
+	status DiffStatus
+	oldKey string
+	info   *FileInfo
+}
+
+type flatNode struct {
+	isLeaf bool
+	info   *FileInfo
+}
+
+func flatten(nodes FileTreeNodes, result map[string]flatNode) map[string]flatNode {
+	for _, node := range nodes {
+		result[node.Key] = flatNode{isLeaf: node.IsLeaf, info: node.Info}
+		if node.Children != nil {
+			flatten(*node.Children, result)
+		}
+	}
+	return result
+}
+
+// DiffFileTrees compares the file trees, and returns the merged tree with the Status of the
+// nodes and the changed files sorted by key.
+//
+// A file is modified when the hashes of both sides are given and differ, or the sizes or the
+// modification times in Info differ. The renamed files are placed at the new keys. When a path
+// changes between a file and a directory, the new node is added, and the old file or the files
+// of the old directory are removed, which are only in the entries since the merged tree can not
+// hold both. The error is returned when a tree has conflicting keys.
+func DiffFileTrees(oldNodes, newNodes FileTreeNodes, opts *DiffOptions) (FileTreeNodes, []DiffEntry, error) {
+	if opts == nil {
+		opts = &DiffOptions{}
+	}
+	oldFlat := flatten(oldNodes, make(map[string]flatNode))
+	newFlat := flatten(newNodes, make(map[string]flatNode))
+
+	merged := New[diffData]()
+	var added, removed []string
+	var entries []DiffEntry
+	typeChanged := make(map[string]bool)
+	for key, node := range newFlat {
+		oldNode, exists := oldFlat[key]
+		data := diffData{info: node.info}
+		switch {
+		case !exists:
+			data.status = StatusAdded
+			if node.isLeaf {
+				added = append(added, key)
+			}
+		case node.isLeaf != oldNode.isLeaf:
+			typeChanged[key] = true
+			data.status = StatusAdded
+			if node.isLeaf {
+				added = append(added, key)
+			} else {
+				removed = append(removed, key)
+			}
+		case node.isLeaf && fileChanged(key, oldNode, node, opts):
+			data.status = StatusModified
+		}
+		if err := insertDiffNode(merged, key, node.isLeaf, data); err != nil {
+			return nil, nil, err
+		}
+	}
+	for key, node := range oldFlat {
+		if _, exists := newFlat[key]; exists {
+			continue
+		}
+		if node.isLeaf {
+			removed = append(removed, key)
+			continue
+		}
+		if underTypeChanged(key, typeChanged) {
+			continue
+		}
+		if err := insertDiffNode(merged, key, false, diffData{status: StatusRemoved, info: node.info}); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	slices.Sort(added)
+	slices.Sort(removed)
+	renamed := make(map[string]bool)
+	if opts.DetectRenames {
+		removedByHash := make(map[string][]string)
+		for _, key := range removed {
+			if hash := opts.OldHashes[key]; hash != "" {
+				removedByHash[hash] = append(removedByHash[hash], key)
+			}
+		}
+		for _, key := range added {
+			candidates := removedByHash[opts.NewHashes[key]]
+			if opts.NewHashes[key] == "" || len(candidates) == 0 {
+				continue
+			}
+			node, _ := merged.Find(key)
+			node.Data.status, node.Data.oldKey = StatusRenamed, candidates[0]
+			removedByHash[opts.NewHashes[key]] = candidates[1:]
+			renamed[candidates[0]] = true
+		}
+	}
+	for _, key := range removed {
+		switch {
+		case renamed[key]:
+		case underTypeChanged(key, typeChanged):
+			entries = append(entries, DiffEntry{Status: StatusRemoved, Key: key})
+		default:
+			if err := insertDiffNode(merged, key, true, diffData{status: StatusRemoved, info: oldFlat[key].info}); err != nil {
+				return nil, nil, err
+			}
+		}
+	}
+
+	markDirs(merged.Root())
+	if opts.OnlyChanges {
+		dropUnchanged(merged, merged.Root())
+	}
+
+	merged.Walk(func(node *Node[diffData], _ int) error {
+		if !node.IsDir() && node.Data.status != StatusUnchanged {
+			entries = append(entries, DiffEntry{Status: node.Data.status, Key: node.Key(), OldKey: node.Data.oldKey})
+		}
+		return nil
+	})
+	slices.SortFunc(entries, func(a, b DiffEntry) int {
+		if c := CompareNatural(a.Key, b.Key); c != 0 {
+			return c
+		}
+		// the removed old file goes before the added new one at the same key
+		return strings.Compare(string(b.Status), string(a.Status))
+	})
+
+	return toFileTreeNodesFunc(merged.Root(), func(node *Node[diffData], ftNode *FileTreeNode) {
+		ftNode.Info = node.Data.info
+		ftNode.Status = node.Data.status
+		ftNode.OldKey = node.Data.oldKey
+	}), entries, nil
+}
+
+// underTypeChanged returns true when the key or one of its parents changed between a file and a directory
+func underTypeChanged(key string, typeChanged map[string]bool) bool {
+	for p := key; p != "" && p != "."; p = path.Dir(p) {
+		if typeChanged[p] {
+			return true
+		}
+	}
+	return false
+}
+
+func fileChanged(key string, oldNode, newNode flatNode, opts *DiffOptions) bool {
+	if oldHash, newHash := opts.OldHashes[key], opts.NewHashes[key]; oldHash != "" && newHash != "" {
+		return oldHash != newHash
+	}
+	if oldNode.info != nil && newNode.info != nil {
+		return oldNode.info.Size != newNode.info.Size || !oldNode.info.ModTime.Equal(newNode.info.ModTime)
+	}
+	return false
+}
+
+func insertDiffNode(t *Tree[diffData], key string, isLeaf bool, data diffData) (err error) {
+	if isLeaf {
+		_, err = t.Insert(key, data)
+	} else {
+		_, err = t.InsertDir(key, data)
+	}
+	if err != nil {
+		err = fmt.Errorf("diff %s: %w", key, err)
+	}
+	return
+}
+
+// markDirs marks the directories existing in both trees as modified when anything inside changed
+func markDirs(node *Node[diffData]) (changed bool) {
+	for _, child := range node.children {
+		if child.IsDir() && markDirs(child) && child.Data.status == StatusUnchanged {
+			child.Data.status = StatusModified
+		}
+		changed = changed || child.Data.status != StatusUnchanged
+	}
+	return
+}
+
+func dropUnchanged(t *Tree[diffData], node *Node[diffData]) {
+	for _, child := range node.children {
+		if child.Data.status == StatusUnchanged {
+			t.size -= countNodes(child)
+			node.removeChild(child)
+			continue
+		}
+		dropUnchanged(t, child)
+	}
+}
+
+// WritePatch writes the changed files in the name-status format of git diff,
+// such as "A\tkey", "D\tkey", "M\tkey" and "R\toldKey\tkey".
+func WritePatch(w io.Writer, entries []DiffEntry) error {
+	for _, entry := range entries {
+		var err error
+		switch entry.Status {
+		case StatusAdded:
+			_, err = fmt.Fprintf(w, "A\t%s\n", entry.Key)
+		case StatusRemoved:
+			_, err = fmt.Fprintf(w, "D\t%s\n", entry.Key)
+		case StatusModified:
+			_, err = fmt.Fprintf(w, "M\t%s\n", entry.Key)
+		case StatusRenamed:
+			_, err = fmt.Fprintf(w, "R\t%s\t%s\n", entry.OldKey, entry.Key)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,128 @@
+package tree
+
+import (
+	"bytes"
+	"encoding/json"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestDiffFileTrees(t *testing.T) {
+	oldNodes := CreateFileTreeLayout([]string{"conf/app.yaml", "conf/db.yaml", "conf/old.yaml", "legacy/x.ini", "README.md"})
+	newNodes := CreateFileTreeLayout([]string{"conf/app.yaml", "conf/db.yaml", "conf/new/moved.yaml", "bin/run.sh", "README.md"})
+	opts := &DiffOptions{
+		OldHashes:     map[string]string{"conf/app.yaml": "1", "conf/db.yaml": "2", "conf/old.yaml": "3", "legacy/x.ini": "4", "README.md": "5"},
+		NewHashes:     map[string]string{"conf/app.yaml": "1", "conf/db.yaml": "22", "conf/new/moved.yaml": "3", "bin/run.sh": "6", "README.md": "5"},
+		DetectRenames: true,
+	}
+	nodes, entries, err := DiffFileTrees(oldNodes, newNodes, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []DiffEntry{
+		{Status: StatusAdded, Key: "bin/run.sh"},
+		{Status: StatusModified, Key: "conf/db.yaml"},
+		{Status: StatusRenamed, Key: "conf/new/moved.yaml", OldKey: "conf/old.yaml"},
+		{Status: StatusRemoved, Key: "legacy/x.ini"},
+	}
+	if !slices.Equal(entries, want) {
+		t.Fatalf("unexpected entries %+v", entries)
+	}
+
+	statuses := make(map[string]DiffStatus)
+	var collect func(FileTreeNodes)
+	collect = func(nodes FileTreeNodes) {
+		for _, node := range nodes {
+			statuses[node.Key] = node.Status
+			if node.Children != nil {
+				collect(*node.Children)
+			}
+		}
+	}
+	collect(nodes)
+	for key, status := range map[string]DiffStatus{
+		"bin":           StatusAdded,
+		"conf":          StatusModified,
+		"conf/new":      StatusAdded,
+		"conf/app.yaml": StatusUnchanged,
+		"legacy":        StatusRemoved,
+		"README.md":     StatusUnchanged,
+	} {
+		if got, exists := statuses[key]; !exists || got != status {
+			t.Errorf("status of %s = %q, want %q", key, got, status)
+		}
+	}
+	if _, exists := statuses["conf/old.yaml"]; exists {
+		t.Error("renamed file should only be at the new key")
+	}
+
+	data, _ := json.Marshal(nodes)
+	if !strings.Contains(string(data), `"status":"renamed","oldKey":"conf/old.yaml"`) {
+		t.Fatalf("unexpected json %s", data)
+	}
+
+	var patch bytes.Buffer
+	WritePatch(&patch, entries)
+	if patch.String() != "A\tbin/run.sh\nM\tconf/db.yaml\nR\tconf/old.yaml\tconf/new/moved.yaml\nD\tlegacy/x.ini\n" {
+		t.Fatalf("unexpected patch %q", patch.String())
+	}
+}
+
+func TestDiffFileTreesOnlyChanges(t *testing.T) {
+	oldNodes := CreateFileTreeLayout([]string{"a/b.txt", "a/c.txt", "d/e.txt"})
+	newNodes := CreateFileTreeLayout([]string{"a/b.txt", "d/e.txt", "d/f.txt"})
+	nodes, entries, _ := DiffFileTrees(oldNodes, newNodes, &DiffOptions{OnlyChanges: true})
+	if got := nodeKeys(nodes); !slices.Equal(got, []string{"a", "a/c.txt", "d", "d/f.txt"}) {
+		t.Fatalf("unexpected keys %v", got)
+	}
+	if len(entries) != 2 || entries[0].Status != StatusRemoved || entries[1].Status != StatusAdded {
+		t.Fatalf("unexpected entries %+v", entries)
+	}
+	// without hashes nor info the common files are unchanged
+	_, entries, _ = DiffFileTrees(oldNodes, oldNodes, nil)
+	if len(entries) != 0 {
+		t.Fatalf("same trees should have no changes, got %+v", entries)
+	}
+}
+
+func TestDiffFileTreesTypeChange(t *testing.T) {
+	oldNodes := CreateFileTreeLayout([]string{"a/b", "a/c/d", "x"})
+	newNodes := CreateFileTreeLayout([]string{"a", "x/y"})
+	nodes, entries, err := DiffFileTrees(oldNodes, newNodes, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []DiffEntry{
+		{Status: StatusAdded, Key: "a"},
+		{Status: StatusRemoved, Key: "a/b"},
+		{Status: StatusRemoved, Key: "a/c/d"},
+		{Status: StatusRemoved, Key: "x"},
+		{Status: StatusAdded, Key: "x/y"},
+	}
+	if !slices.Equal(entries, want) {
+		t.Fatalf("unexpected entries %+v", entries)
+	}
+	if len(nodes) != 2 || !nodes[1].IsLeaf || nodes[1].Key != "a" || nodes[1].Status != StatusAdded ||
+		nodes[0].Key != "x" || nodes[0].Status != StatusAdded || (*nodes[0].Children)[0].Key != "x/y" {
+		t.Fatalf("new nodes should be added, got %+v", nodes)
+	}
+
+	var buf bytes.Buffer
+	WritePatch(&buf, entries)
+	if buf.String() != "A\ta\nD\ta/b\nD\ta/c/d\nD\tx\nA\tx/y\n" {
+		t.Fatalf("unexpected patch\n%s", buf.String())
+	}
+}
+
+func TestDiffFileTreesConflict(t *testing.T) {
+	// the malformed tree holds a file under a file
+	newNodes := FileTreeNodes{
+		{Key: "a", IsLeaf: true},
+		{Key: "a/b", IsLeaf: true},
+	}
+	if _, _, err := DiffFileTrees(nil, newNodes, nil); err == nil {
+		t.Fatal("conflicting keys should be reported")
+	}
+}
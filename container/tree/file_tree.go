@@ -9,8 +9,11 @@ type FileTreeNode struct {
 	Path       string         `json:"-"`
 	Title      string         `json:"title"`
 	Key        string         `json:"key"`
-	Selectable bool           `json:"selectable"` // set to true when file
-	IsLeaf     bool           `json:"isLeaf"`     // set to true when file
+	Selectable bool           `json:"selectable"`       // set to true when file
+	IsLeaf     bool           `json:"isLeaf"`           // set to true when file
+	Info       *FileInfo      `json:"info,omitempty"`   // set when built from a file system or an archive
+	Status     DiffStatus     `json:"status,omitempty"` // set by DiffFileTrees
+	OldKey     string         `json:"oldKey,omitempty"` // set by DiffFileTrees when renamed
 	Children   *FileTreeNodes `json:"children,omitempty"`
 }
 
@@ -20,36 +23,89 @@ func (t *FileTreeNodes) Append(node FileTreeNode) {
 	*t = append(*t, node)
 }
 
+// CreateFileTreeLayout creates the file tree from the slash separated paths,
+// the nodes are in the order of their first appearance in filePaths.
 func CreateFileTreeLayout(filePaths []string) (rootTreeNodes FileTreeNodes) {
 	rootTreeNodes = make(FileTreeNodes, 0)
+	// index of the children by name, the nodes are only appended so the positions are stable
+	index := make(map[*FileTreeNodes]map[string]int)
 	for _, path := range filePaths {
 		var current = &rootTreeNodes
 		pathItems := strings.Split(path, "/")
 		pathItemsCnt := len(pathItems)
-		for index, pathItem := range pathItems {
-			var exists bool
-			for i := 0; i < len(*current); i++ {
-				if (*current)[i].Path == pathItem {
-					exists = true
-					current = (*current)[i].Children
-					break
-				}
+		for i, pathItem := range pathItems {
+			names, ok := index[current]
+			if !ok {
+				names = make(map[string]int)
+				index[current] = names
 			}
-			if !exists {
-				children := new(FileTreeNodes)
-				isLeaf := pathItemsCnt-1 == index
-				childNode := FileTreeNode{
-					Path:       pathItem,
-					Title:      pathItem,
-					Key:        filepath.Join(pathItems[:index+1]...),
-					Children:   children,
-					IsLeaf:     isLeaf,
-					Selectable: isLeaf,
-				}
-				current.Append(childNode)
-				current = childNode.Children
+			if pos, exists := names[pathItem]; exists {
+				current = (*current)[pos].Children
+				continue
 			}
+			children := new(FileTreeNodes)
+			isLeaf := pathItemsCnt-1 == i
+			childNode := FileTreeNode{
+				Path:       pathItem,
+				Title:      pathItem,
+				Key:        filepath.Join(pathItems[:i+1]...),
+				Children:   children,
+				IsLeaf:     isLeaf,
+				Selectable: isLeaf,
+			}
+			names[pathItem] = len(*current)
+			current.Append(childNode)
+			current = childNode.Children
 		}
 	}
 	return
 }
+
+// ToFileTreeNodes converts the tree to the file tree layout, the nodes are in the sorted order of Node.Children
+func ToFileTreeNodes[T any](t *Tree[T]) FileTreeNodes {
+	return toFileTreeNodesFunc(t.Root(), nil)
+}
+
+// ToFileTreeNodesWithInfo converts the tree to the file tree layout with the metadata in Info
+func ToFileTreeNodesWithInfo(t *Tree[FileInfo]) FileTreeNodes {
+	return toFileTreeNodesFunc(t.Root(), func(node *Node[FileInfo], ftNode *FileTreeNode) {
+		info := node.Data
+		ftNode.Info = &info
+	})
+}
+
+// toFileTreeNodesFunc converts the children of parent, fill sets the extra fields from the payload
+func toFileTreeNodesFunc[T any](parent *Node[T], fill func(*Node[T], *FileTreeNode)) FileTreeNodes {
+	nodes := make(FileTreeNodes, 0, parent.Len())
+	for _, child := range parent.Children() {
+		// leaves have empty children as CreateFileTreeLayout does
+		var children FileTreeNodes
+		if child.IsDir() {
+			children = toFileTreeNodesFunc(child, fill)
+		}
+		node := FileTreeNode{
+			Path:       child.Name(),
+			Title:      child.Name(),
+			Key:        child.Key(),
+			Children:   &children,
+			IsLeaf:     !child.IsDir(),
+			Selectable: !child.IsDir(),
+		}
+		if fill != nil {
+			fill(child, &node)
+		}
+		nodes.Append(node)
+	}
+	return nodes
+}
+
+// BuildTree creates the tree from the slash separated file paths, which are the leaves
+func BuildTree(filePaths []string) (*Tree[struct{}], error) {
+	t := New[struct{}]()
+	for _, p := range filePaths {
+		if _, err := t.Insert(p, struct{}{}); err != nil {
+			return nil, err
+		}
+	}
+	return t, nil
+}
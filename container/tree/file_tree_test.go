@@ -0,0 +1,34 @@
+package tree
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestCreateFileTreeLayout(t *testing.T) {
+	nodes := CreateFileTreeLayout([]string{"b/x.txt", "a.txt", "b/c/y.txt", "b/x.txt"})
+	data, err := json.Marshal(nodes)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `[{"title":"b","key":"b","selectable":false,"isLeaf":false,"children":[` +
+		`{"title":"x.txt","key":"b/x.txt","selectable":true,"isLeaf":true,"children":null},` +
+		`{"title":"c","key":"b/c","selectable":false,"isLeaf":false,"children":[` +
+		`{"title":"y.txt","key":"b/c/y.txt","selectable":true,"isLeaf":true,"children":null}]}]},` +
+		`{"title":"a.txt","key":"a.txt","selectable":true,"isLeaf":true,"children":null}]`
+	if string(data) != want {
+		t.Fatalf("unexpected layout %s", data)
+	}
+}
+
+func TestToFileTreeNodes(t *testing.T) {
+	tr, _ := BuildTree([]string{"b/x.txt", "a.txt", "b/c/y.txt"})
+	nodes := ToFileTreeNodes(tr)
+	if len(nodes) != 2 || nodes[0].Key != "b" || nodes[1].Key != "a.txt" || !nodes[1].IsLeaf {
+		t.Fatalf("directories should be first, got %+v", nodes)
+	}
+	children := *nodes[0].Children
+	if len(children) != 2 || children[0].Key != "b/c" || children[1].Key != "b/x.txt" {
+		t.Fatalf("unexpected children %+v", children)
+	}
+}
@@ -0,0 +1,208 @@
+package tree
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path"
+	"time"
+)
+
+// FileInfo is the metadata of a file tree node
+type FileInfo struct {
+	Size    int64       `json:"size"` // aggregated size of the files inside for a directory
+	ModTime time.Time   `json:"modTime"`
+	Mode    fs.FileMode `json:"mode"`
+}
+
+// SymlinkPolicy tells how the symbolic links are handled when building the file tree
+type SymlinkPolicy int
+
+const (
+	SymlinkAsFile SymlinkPolicy = iota // keep the link as a leaf without following it
+	SymlinkSkip                        // skip the link
+	SymlinkFollow                      // follow the link, the links to an ancestor directory are skipped
+)
+
+// BuildOptions is the options to build the file tree from a file system or an archive
+type BuildOptions struct {
+	Include    []string      // glob patterns of the files to keep, all files are kept when empty
+	Exclude    []string      // .gitignore-style patterns of the files and directories to skip
+	IgnoreFile string        // name of the .gitignore-style files to read in each directory, such as ".gitignore"
+	MaxDepth   int           // max depth of the nodes, 1 for the top level only, 0 means unlimited; deeper files are not counted in the sizes
+	Symlinks   SymlinkPolicy // only for file systems, the links in archives are always leaves
+	PruneEmpty bool          // remove the directories without files except those at MaxDepth, implied by Include
+}
+
+type builder struct {
+	opts    BuildOptions
+	ignore  *IgnoreRules
+	include *IgnoreRules
+	tree    *Tree[FileInfo]
+}
+
+func newBuilder(opts *BuildOptions) (*builder, error) {
+	b := &builder{tree: New[FileInfo]()}
+	if opts != nil {
+		b.opts = *opts
+	}
+	var err error
+	if b.ignore, err = NewIgnoreRules(b.opts.Exclude...); err != nil {
+		return nil, err
+	}
+	if b.include, err = NewIgnoreRules(b.opts.Include...); err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+func (b *builder) keepFile(p string) bool {
+	return b.include.Len() == 0 || b.include.Match(p, false)
+}
+
+func (b *builder) finish() *Tree[FileInfo] {
+	if b.opts.PruneEmpty || b.include.Len() > 0 {
+		b.tree.pruneEmptyDirs(b.opts.MaxDepth)
+	}
+	aggregate(b.tree.Root())
+	return b.tree
+}
+
+// aggregate sums the sizes of the files into the directories, the directory without
+// a modification time takes the latest one of its children
+func aggregate(node *Node[FileInfo]) {
+	if !node.IsDir() {
+		return
+	}
+	var size int64
+	inherit := node.Data.ModTime.IsZero()
+	for _, child := range node.children {
+		aggregate(child)
+		size += child.Data.Size
+		if inherit && child.Data.ModTime.After(node.Data.ModTime) {
+			node.Data.ModTime = child.Data.ModTime
+		}
+	}
+	node.Data.Size = size
+	node.Data.Mode |= fs.ModeDir
+}
+
+// BuildTreeFS builds the tree of the file system with the metadata of the nodes
+func BuildTreeFS(fsys fs.FS, opts *BuildOptions) (*Tree[FileInfo], error) {
+	b, err := newBuilder(opts)
+	if err != nil {
+		return nil, err
+	}
+	rootInfo, err := fs.Stat(fsys, ".")
+	if err != nil {
+		return nil, err
+	}
+	if err = b.walkFS(fsys, ".", 1, []fs.FileInfo{rootInfo}); err != nil {
+		return nil, err
+	}
+	return b.finish(), nil
+}
+
+// BuildTreeDir builds the tree of the directory with the metadata of the nodes
+func BuildTreeDir(dir string, opts *BuildOptions) (*Tree[FileInfo], error) {
+	return BuildTreeFS(os.DirFS(dir), opts)
+}
+
+// FromFS creates the file tree of the file system, the nodes carry their metadata in Info
+func FromFS(fsys fs.FS, opts *BuildOptions) (FileTreeNodes, error) {
+	t, err := BuildTreeFS(fsys, opts)
+	if err != nil {
+		return nil, err
+	}
+	return ToFileTreeNodesWithInfo(t), nil
+}
+
+// FromDir creates the file tree of the directory, the nodes carry their metadata in Info
+func FromDir(dir string, opts *BuildOptions) (FileTreeNodes, error) {
+	return FromFS(os.DirFS(dir), opts)
+}
+
+func (b *builder) walkFS(fsys fs.FS, dir string, depth int, ancestors []fs.FileInfo) error {
+	if b.opts.IgnoreFile != "" {
+		if err := b.readIgnoreFile(fsys, dir); err != nil {
+			return err
+		}
+	}
+	entries, err := fs.ReadDir(fsys, dir)
+	if err != nil {
+		return err
+	}
+	for _, entry := range entries {
+		p := path.Join(dir, entry.Name())
+		info, err := entry.Info()
+		if err != nil {
+			if errors.Is(err, fs.ErrNotExist) {
+				// removed during the walk
+				continue
+			}
+			return err
+		}
+		isDir := entry.IsDir()
+		if entry.Type()&fs.ModeSymlink != 0 {
+			switch b.opts.Symlinks {
+			case SymlinkSkip:
+				continue
+			case SymlinkFollow:
+				if target, err := fs.Stat(fsys, p); err == nil {
+					if target.IsDir() && isAncestor(target, ancestors) {
+						continue
+					}
+					info, isDir = target, target.IsDir()
+				}
+			}
+		}
+		if b.ignore.Match(p, isDir) {
+			continue
+		}
+		data := FileInfo{Size: info.Size(), ModTime: info.ModTime(), Mode: info.Mode()}
+		if !isDir {
+			if b.keepFile(p) {
+				if _, err = b.tree.Insert(p, data); err != nil {
+					return err
+				}
+			}
+			continue
+		}
+		if _, err = b.tree.InsertDir(p, data); err != nil {
+			return err
+		}
+		if b.opts.MaxDepth > 0 && depth >= b.opts.MaxDepth {
+			continue
+		}
+		if err = b.walkFS(fsys, p, depth+1, append(ancestors[:len(ancestors):len(ancestors)], info)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (b *builder) readIgnoreFile(fsys fs.FS, dir string) error {
+	f, err := fsys.Open(path.Join(dir, b.opts.IgnoreFile))
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil
+		}
+		return err
+	}
+	defer f.Close()
+	base := dir
+	if base == "." {
+		base = ""
+	}
+	return b.ignore.Read(base, f)
+}
+
+// isAncestor returns true when the directory is one of the ancestors, which means a cycle
+func isAncestor(dir fs.FileInfo, ancestors []fs.FileInfo) bool {
+	for _, ancestor := range ancestors {
+		if os.SameFile(dir, ancestor) {
+			return true
+		}
+	}
+	return false
+}
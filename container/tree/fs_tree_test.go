@@ -0,0 +1,115 @@
+package tree
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"testing/fstest"
+	"time"
+)
+
+var testModTime = time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+
+func testFS() fstest.MapFS {
+	file := func(data string) *fstest.MapFile {
+		return &fstest.MapFile{Data: []byte(data), Mode: 0644, ModTime: testModTime}
+	}
+	return fstest.MapFS{
+		".gitignore":         file("*.log\nvendor/\n"),
+		"main.go":            file("package main"),
+		"debug.log":          file("log"),
+		"vendor/lib/lib.go":  file("package lib"),
+		"pkg/a.go":           file("package pkg"),
+		"pkg/b_test.go":      file("package pkg_test"),
+		"pkg/.gitignore":     file("*_test.go\n"),
+		"pkg/deep/x/y.go":    file("package x"),
+		"assets/logo.png":    file("0123456789"),
+		"assets/empty/.keep": file(""),
+	}
+}
+
+func nodeKeys(nodes FileTreeNodes) []string {
+	var keys []string
+	for _, node := range nodes {
+		keys = append(keys, node.Key)
+		if node.Children != nil {
+			keys = append(keys, nodeKeys(*node.Children)...)
+		}
+	}
+	return keys
+}
+
+func TestBuildTreeFS(t *testing.T) {
+	tr, err := BuildTreeFS(testFS(), &BuildOptions{
+		IgnoreFile: ".gitignore",
+		Exclude:    []string{".gitignore", ".keep"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"assets", "assets/empty", "assets/logo.png", "pkg", "pkg/deep", "pkg/deep/x", "pkg/deep/x/y.go", "pkg/a.go", "main.go"}
+	if got := nodeKeys(ToFileTreeNodesWithInfo(tr)); !slices.Equal(got, want) {
+		t.Fatalf("unexpected keys %v", got)
+	}
+	pkg, _ := tr.Find("pkg")
+	if pkg.Data.Size != int64(len("package x")+len("package pkg")) || !pkg.Data.Mode.IsDir() {
+		t.Fatalf("unexpected pkg info %+v", pkg.Data)
+	}
+	logo, _ := tr.Find("assets/logo.png")
+	if logo.Data.Size != 10 || !logo.Data.ModTime.Equal(testModTime) || logo.Data.Mode != 0644 {
+		t.Fatalf("unexpected logo info %+v", logo.Data)
+	}
+	if tr.Root().Data.Size != 10+9+11+12 {
+		t.Fatalf("unexpected total size %d", tr.Root().Data.Size)
+	}
+}
+
+func TestBuildTreeFSIncludeAndDepth(t *testing.T) {
+	nodes, err := FromFS(testFS(), &BuildOptions{Include: []string{"*.go"}, Exclude: []string{"vendor/"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"pkg", "pkg/deep", "pkg/deep/x", "pkg/deep/x/y.go", "pkg/a.go", "pkg/b_test.go", "main.go"}
+	if got := nodeKeys(nodes); !slices.Equal(got, want) {
+		t.Fatalf("unexpected keys %v", got)
+	}
+	if nodes[0].Info == nil || nodes[0].Info.Size == 0 {
+		t.Fatal("nodes should carry the info")
+	}
+
+	nodes, err = FromFS(testFS(), &BuildOptions{MaxDepth: 2, Include: []string{"*.go"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the directories at max depth are kept as their files are not listed
+	want = []string{"assets", "assets/empty", "pkg", "pkg/deep", "pkg/a.go", "pkg/b_test.go", "vendor", "vendor/lib", "main.go"}
+	if got := nodeKeys(nodes); !slices.Equal(got, want) {
+		t.Fatalf("unexpected keys with max depth %v", got)
+	}
+}
+
+func TestBuildTreeDirSymlinks(t *testing.T) {
+	dir := t.TempDir()
+	os.MkdirAll(filepath.Join(dir, "real/sub"), 0755)
+	os.WriteFile(filepath.Join(dir, "real/sub/f.txt"), []byte("hello"), 0644)
+	if err := os.Symlink(filepath.Join(dir, "real"), filepath.Join(dir, "link")); err != nil {
+		t.Skip("symlink not supported", err)
+	}
+	// a cycle back to the root
+	os.Symlink(dir, filepath.Join(dir, "real/sub/loop"))
+
+	for policy, want := range map[SymlinkPolicy][]string{
+		SymlinkSkip:   {"real", "real/sub", "real/sub/f.txt"},
+		SymlinkAsFile: {"real", "real/sub", "real/sub/f.txt", "real/sub/loop", "link"},
+		SymlinkFollow: {"link", "link/sub", "link/sub/f.txt", "real", "real/sub", "real/sub/f.txt"},
+	} {
+		nodes, err := FromDir(dir, &BuildOptions{Symlinks: policy})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := nodeKeys(nodes); !slices.Equal(got, want) {
+			t.Fatalf("policy %d: unexpected keys %v", policy, got)
+		}
+	}
+}
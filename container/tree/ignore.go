@@ -0,0 +1,173 @@
+package tree
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+)
+
+// IgnoreRules is a list of .gitignore-style patterns, the last matching pattern wins.
+//
+// A pattern without a slash matches the name at any level, otherwise it is anchored to
+// the base directory of the rules. "*", "?", "[...]" and "**" are supported, a trailing
+// slash matches only directories and a leading "!" negates the pattern.
+type IgnoreRules struct {
+	rules []ignoreRule
+}
+
+type ignoreRule struct {
+	base     string
+	re       *regexp.Regexp
+	negate   bool
+	dirOnly  bool
+	anchored bool
+}
+
+// NewIgnoreRules returns the rules of the patterns relative to the root
+func NewIgnoreRules(patterns ...string) (*IgnoreRules, error) {
+	r := &IgnoreRules{}
+	if err := r.Add("", patterns...); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// Len returns the number of rules
+func (r *IgnoreRules) Len() int {
+	return len(r.rules)
+}
+
+// Add appends the patterns relative to the base directory, blank lines and comments are skipped
+func (r *IgnoreRules) Add(base string, patterns ...string) error {
+	for _, pattern := range patterns {
+		rule, ok, err := compileIgnoreRule(base, pattern)
+		if err != nil {
+			return err
+		}
+		if ok {
+			r.rules = append(r.rules, rule)
+		}
+	}
+	return nil
+}
+
+// Read appends the patterns read from the .gitignore-style file, the invalid patterns are skipped as git does
+func (r *IgnoreRules) Read(base string, reader io.Reader) error {
+	scanner := bufio.NewScanner(reader)
+	for scanner.Scan() {
+		if rule, ok, err := compileIgnoreRule(base, scanner.Text()); err == nil && ok {
+			r.rules = append(r.rules, rule)
+		}
+	}
+	return scanner.Err()
+}
+
+// Match returns true when the slash separated path is matched, the negated patterns
+// unmatch the path. The parent directories are not checked, use MatchAll for that.
+func (r *IgnoreRules) Match(p string, isDir bool) bool {
+	matched := false
+	for _, rule := range r.rules {
+		if rule.match(p, isDir) {
+			matched = !rule.negate
+		}
+	}
+	return matched
+}
+
+// MatchAll returns true when the path or any of its parent directories is matched
+func (r *IgnoreRules) MatchAll(p string, isDir bool) bool {
+	for i := 0; i < len(p); i++ {
+		if p[i] == '/' && r.Match(p[:i], true) {
+			return true
+		}
+	}
+	return r.Match(p, isDir)
+}
+
+func (rule ignoreRule) match(p string, isDir bool) bool {
+	if rule.dirOnly && !isDir {
+		return false
+	}
+	if rule.base != "" {
+		if !strings.HasPrefix(p, rule.base+"/") {
+			return false
+		}
+		p = p[len(rule.base)+1:]
+	}
+	if !rule.anchored {
+		p = p[strings.LastIndexByte(p, '/')+1:]
+	}
+	return rule.re.MatchString(p)
+}
+
+func compileIgnoreRule(base, pattern string) (rule ignoreRule, ok bool, err error) {
+	pattern = strings.TrimRight(pattern, " \t\r")
+	if pattern == "" || strings.HasPrefix(pattern, "#") {
+		return
+	}
+	rule.base = strings.Trim(base, "/")
+	if strings.HasPrefix(pattern, "!") {
+		rule.negate = true
+		pattern = pattern[1:]
+	} else if strings.HasPrefix(pattern, `\!`) || strings.HasPrefix(pattern, `\#`) {
+		pattern = pattern[1:]
+	}
+	if strings.HasSuffix(pattern, "/") {
+		rule.dirOnly = true
+		pattern = strings.TrimRight(pattern, "/")
+	}
+	if pattern == "" {
+		return
+	}
+	rule.anchored = strings.Contains(pattern, "/")
+	pattern = strings.TrimPrefix(pattern, "/")
+	expr, err := globToRegexp(pattern)
+	if err != nil {
+		return rule, false, err
+	}
+	if rule.re, err = regexp.Compile(expr); err != nil {
+		return rule, false, fmt.Errorf("tree: invalid pattern %q, %w", pattern, err)
+	}
+	return rule, true, nil
+}
+
+// globToRegexp converts the glob with "**" support to an anchored regular expression
+func globToRegexp(glob string) (string, error) {
+	var b strings.Builder
+	b.WriteString("^")
+	for i := 0; i < len(glob); i++ {
+		c := glob[i]
+		switch {
+		case strings.HasPrefix(glob[i:], "**/") && (i == 0 || glob[i-1] == '/'):
+			b.WriteString("(?:.*/)?")
+			i += 2
+		case glob[i:] == "**" && (i == 0 || glob[i-1] == '/'):
+			b.WriteString(".*")
+			i++
+		case c == '*':
+			b.WriteString("[^/]*")
+		case c == '?':
+			b.WriteString("[^/]")
+		case c == '\\' && i+1 < len(glob):
+			i++
+			b.WriteString(regexp.QuoteMeta(glob[i : i+1]))
+		case c == '[':
+			end := strings.IndexByte(glob[i+1:], ']')
+			if end < 0 {
+				return "", fmt.Errorf("tree: invalid pattern %q, unclosed bracket", glob)
+			}
+			class := glob[i+1 : i+1+end]
+			if strings.HasPrefix(class, "!") {
+				class = "^" + class[1:]
+			}
+			b.WriteString("[" + strings.ReplaceAll(class, `\`, `\\`) + "]")
+			i += end + 1
+		default:
+			b.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+	b.WriteString("$")
+	return b.String(), nil
+}
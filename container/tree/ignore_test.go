@@ -0,0 +1,72 @@
+package tree
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestIgnoreRules(t *testing.T) {
+	rules, err := NewIgnoreRules(
+		"# comment",
+		"*.log",
+		"!keep.log",
+		"build/",
+		"/root.txt",
+		"docs/**/*.tmp",
+		"**/cache",
+		"file[0-9].txt",
+		`\#hash`,
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cases := []struct {
+		path    string
+		isDir   bool
+		ignored bool
+	}{
+		{"a.log", false, true},
+		{"x/y/a.log", false, true},
+		{"x/keep.log", false, false},
+		{"build", true, true},
+		{"x/build", true, true},
+		{"build", false, false},
+		{"root.txt", false, true},
+		{"x/root.txt", false, false},
+		{"docs/a.tmp", false, true},
+		{"docs/x/y/a.tmp", false, true},
+		{"other/a.tmp", false, false},
+		{"cache", true, true},
+		{"a/b/cache", false, true},
+		{"file1.txt", false, true},
+		{"file10.txt", false, false},
+		{"#hash", false, true},
+	}
+	for _, c := range cases {
+		if got := rules.Match(c.path, c.isDir); got != c.ignored {
+			t.Errorf("match %q (dir=%v) = %v, want %v", c.path, c.isDir, got, c.ignored)
+		}
+	}
+	if !rules.MatchAll("build/out/a.txt", false) {
+		t.Fatal("file inside an ignored dir should be matched")
+	}
+	if _, err = NewIgnoreRules("[abc"); err == nil {
+		t.Fatal("unclosed bracket should be rejected")
+	}
+}
+
+func TestIgnoreRulesBase(t *testing.T) {
+	rules := &IgnoreRules{}
+	if err := rules.Read("sub", strings.NewReader("*.txt\n/only.md\n[bad\n")); err != nil {
+		t.Fatal(err)
+	}
+	if rules.Len() != 2 {
+		t.Fatalf("invalid pattern should be skipped, got %d rules", rules.Len())
+	}
+	if !rules.Match("sub/a/b.txt", false) || rules.Match("b.txt", false) {
+		t.Fatal("rules should apply under the base only")
+	}
+	if !rules.Match("sub/only.md", false) || rules.Match("sub/a/only.md", false) {
+		t.Fatal("anchored rule should be relative to the base")
+	}
+}
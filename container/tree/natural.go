@@ -0,0 +1,65 @@
+package tree
+
+import "strings"
+
+// CompareNatural compares the names in natural order, the digit runs are compared by
+// their numeric values, so "file2" < "file10". Letters are compared case-insensitively
+// first and then case-sensitively, so the order is deterministic.
+func CompareNatural(a, b string) int {
+	if c := compareNatural(strings.ToLower(a), strings.ToLower(b)); c != 0 {
+		return c
+	}
+	return compareNatural(a, b)
+}
+
+func compareNatural(a, b string) int {
+	i, j, zeros := 0, 0, 0
+	for i < len(a) && j < len(b) {
+		if isDigit(a[i]) && isDigit(b[j]) {
+			si, sj := i, j
+			for i < len(a) && isDigit(a[i]) {
+				i++
+			}
+			for j < len(b) && isDigit(b[j]) {
+				j++
+			}
+			// compare the numbers without the leading zeros, the shorter is smaller
+			na, nb := strings.TrimLeft(a[si:i], "0"), strings.TrimLeft(b[sj:j], "0")
+			if len(na) != len(nb) {
+				return compareInt(len(na), len(nb))
+			}
+			if c := strings.Compare(na, nb); c != 0 {
+				return c
+			}
+			// "01" after "1" when the rest is equal
+			if zeros == 0 {
+				zeros = compareInt(i-si, j-sj)
+			}
+			continue
+		}
+		if a[i] != b[j] {
+			return compareInt(int(a[i]), int(b[j]))
+		}
+		i++
+		j++
+	}
+	if c := compareInt(len(a)-i, len(b)-j); c != 0 {
+		return c
+	}
+	return zeros
+}
+
+func isDigit(c byte) bool {
+	return '0' <= c && c <= '9'
+}
+
+func compareInt(a, b int) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	default:
+		return 0
+	}
+}
@@ -0,0 +1,233 @@
+package tree
+
+import (
+	"bufio"
+	"fmt"
+	"html"
+	"io"
+	"io/fs"
+	"strings"
+)
+
+// RenderOptions is the options of the renderers
+type RenderOptions struct {
+	ASCII    bool   // draw the text tree with ASCII instead of Unicode box drawing
+	MaxDepth int    // max depth of the rendered nodes, 1 for the top level only, 0 means unlimited
+	ShowSize bool   // show the human readable sizes from Info
+	Color    bool   // colorize the text tree with ANSI escapes, the other formats use css classes
+	Root     string // the first line of the text tree, such as "."; nothing is printed when empty
+}
+
+const (
+	ansiReset    = "\x1b[0m"
+	ansiDir      = "\x1b[1;34m"
+	ansiSymlink  = "\x1b[1;36m"
+	ansiExec     = "\x1b[1;32m"
+	ansiAdded    = "\x1b[32m"
+	ansiRemoved  = "\x1b[31m"
+	ansiModified = "\x1b[33m"
+	ansiRenamed  = "\x1b[35m"
+)
+
+type treeGlyphs struct {
+	branch, last, pipe, blank string
+}
+
+var (
+	unicodeGlyphs = treeGlyphs{branch: "├── ", last: "└── ", pipe: "│   ", blank: "    "}
+	asciiGlyphs   = treeGlyphs{branch: "|-- ", last: "`-- ", pipe: "|   ", blank: "    "}
+)
+
+func renderOptions(opts *RenderOptions) *RenderOptions {
+	if opts == nil {
+		return &RenderOptions{}
+	}
+	return opts
+}
+
+func (opts *RenderOptions) expand(depth int) bool {
+	return opts.MaxDepth <= 0 || depth < opts.MaxDepth
+}
+
+func childrenOf(node *FileTreeNode) FileTreeNodes {
+	if node.Children == nil {
+		return nil
+	}
+	return *node.Children
+}
+
+// FormatSize formats the size in bytes like tree -h, such as "512", "1.5K" and "3.0G"
+func FormatSize(size int64) string {
+	const unit = 1024
+	if size < unit {
+		return fmt.Sprintf("%d", size)
+	}
+	value, exp := float64(size)/unit, 0
+	for value >= unit && exp < 5 {
+		value /= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f%c", value, "KMGTPE"[exp])
+}
+
+// RenderText writes the tree like tree(1), ended with the count of the directories and the files
+func RenderText(w io.Writer, nodes FileTreeNodes, opts *RenderOptions) error {
+	opts = renderOptions(opts)
+	glyphs := unicodeGlyphs
+	if opts.ASCII {
+		glyphs = asciiGlyphs
+	}
+	bw := bufio.NewWriter(w)
+	if opts.Root != "" {
+		bw.WriteString(opts.Root + "\n")
+	}
+	var dirs, files int
+	var render func(nodes FileTreeNodes, prefix string, depth int)
+	render = func(nodes FileTreeNodes, prefix string, depth int) {
+		for i := range nodes {
+			node := &nodes[i]
+			glyph, next := glyphs.branch, glyphs.pipe
+			if i == len(nodes)-1 {
+				glyph, next = glyphs.last, glyphs.blank
+			}
+			bw.WriteString(prefix + glyph + textLabel(node, opts) + "\n")
+			if node.IsLeaf {
+				files++
+				continue
+			}
+			dirs++
+			if opts.expand(depth + 1) {
+				render(childrenOf(node), prefix+next, depth+1)
+			}
+		}
+	}
+	render(nodes, "", 0)
+	fmt.Fprintf(bw, "\n%d %s, %d %s\n", dirs, plural(dirs, "directory", "directories"), files, plural(files, "file", "files"))
+	return bw.Flush()
+}
+
+func plural(n int, one, many string) string {
+	if n == 1 {
+		return one
+	}
+	return many
+}
+
+func textLabel(node *FileTreeNode, opts *RenderOptions) string {
+	var b strings.Builder
+	if opts.ShowSize && node.Info != nil {
+		fmt.Fprintf(&b, "[%5s]  ", FormatSize(node.Info.Size))
+	}
+	name := node.Title
+	if opts.Color {
+		if color := nodeColor(node); color != "" {
+			name = color + name + ansiReset
+		}
+	}
+	b.WriteString(name)
+	switch node.Status {
+	case StatusUnchanged:
+	case StatusRenamed:
+		fmt.Fprintf(&b, " (renamed from %s)", node.OldKey)
+	default:
+		fmt.Fprintf(&b, " (%s)", node.Status)
+	}
+	return b.String()
+}
+
+func nodeColor(node *FileTreeNode) string {
+	switch node.Status {
+	case StatusAdded:
+		return ansiAdded
+	case StatusRemoved:
+		return ansiRemoved
+	case StatusRenamed:
+		return ansiRenamed
+	case StatusModified:
+		if node.IsLeaf {
+			return ansiModified
+		}
+	}
+	switch {
+	case !node.IsLeaf:
+		return ansiDir
+	case node.Info != nil && node.Info.Mode&fs.ModeSymlink != 0:
+		return ansiSymlink
+	case node.Info != nil && node.Info.Mode&0111 != 0:
+		return ansiExec
+	}
+	return ""
+}
+
+var markdownEscaper = strings.NewReplacer(
+	`\`, `\\`, "`", "\\`", "*", `\*`, "_", `\_`, "[", `\[`, "]", `\]`, "<", `\<`, ">", `\>`, "#", `\#`,
+)
+
+// RenderMarkdown writes the tree as Markdown nested lists, the directories are in bold with a trailing slash
+func RenderMarkdown(w io.Writer, nodes FileTreeNodes, opts *RenderOptions) error {
+	opts = renderOptions(opts)
+	bw := bufio.NewWriter(w)
+	var render func(nodes FileTreeNodes, depth int)
+	render = func(nodes FileTreeNodes, depth int) {
+		for i := range nodes {
+			node := &nodes[i]
+			name := markdownEscaper.Replace(node.Title)
+			if !node.IsLeaf {
+				name = "**" + name + "/**"
+			}
+			bw.WriteString(strings.Repeat("  ", depth) + "- " + name)
+			if opts.ShowSize && node.Info != nil {
+				bw.WriteString(" (" + FormatSize(node.Info.Size) + ")")
+			}
+			if node.Status != StatusUnchanged {
+				bw.WriteString(" _" + string(node.Status) + "_")
+			}
+			bw.WriteString("\n")
+			if !node.IsLeaf && opts.expand(depth+1) {
+				render(childrenOf(node), depth+1)
+			}
+		}
+	}
+	render(nodes, 0)
+	return bw.Flush()
+}
+
+// RenderHTML writes the tree as nested <ul> lists, the items have the css classes "dir" or "file"
+// and the diff status, and the keys in the data-key attributes.
+func RenderHTML(w io.Writer, nodes FileTreeNodes, opts *RenderOptions) error {
+	opts = renderOptions(opts)
+	bw := bufio.NewWriter(w)
+	var render func(nodes FileTreeNodes, depth int)
+	render = func(nodes FileTreeNodes, depth int) {
+		indent := strings.Repeat("  ", depth)
+		if depth == 0 {
+			bw.WriteString(`<ul class="file-tree">` + "\n")
+		} else {
+			bw.WriteString(indent + "<ul>\n")
+		}
+		for i := range nodes {
+			node := &nodes[i]
+			class := "file"
+			if !node.IsLeaf {
+				class = "dir"
+			}
+			if node.Status != StatusUnchanged {
+				class += " " + string(node.Status)
+			}
+			fmt.Fprintf(bw, `%s  <li class="%s" data-key="%s">%s`, indent, class, html.EscapeString(node.Key), html.EscapeString(node.Title))
+			if opts.ShowSize && node.Info != nil {
+				fmt.Fprintf(bw, ` <span class="size">%s</span>`, FormatSize(node.Info.Size))
+			}
+			children := childrenOf(node)
+			if !node.IsLeaf && len(children) > 0 && opts.expand(depth+1) {
+				bw.WriteString("\n")
+				render(children, depth+1)
+				bw.WriteString(indent + "  ")
+			}
+			bw.WriteString("</li>\n")
+		}
+		bw.WriteString(indent + "</ul>\n")
+	}
+	render(nodes, 0)
+	return bw.Flush()
+}
@@ -0,0 +1,100 @@
+package tree
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func renderTree() FileTreeNodes {
+	return ToFileTreeNodes(must(BuildTree([]string{"src/main.go", "src/lib/a_b.go", "README.md"})))
+}
+
+func TestRenderText(t *testing.T) {
+	var buf bytes.Buffer
+	if err := RenderText(&buf, renderTree(), &RenderOptions{Root: "."}); err != nil {
+		t.Fatal(err)
+	}
+	want := `.
+├── src
+│   ├── lib
+│   │   └── a_b.go
+│   └── main.go
+└── README.md
+
+2 directories, 3 files
+`
+	if buf.String() != want {
+		t.Fatalf("unexpected text\n%s", buf.String())
+	}
+
+	buf.Reset()
+	RenderText(&buf, renderTree(), &RenderOptions{ASCII: true, MaxDepth: 2})
+	want = "|-- src\n|   |-- lib\n|   `-- main.go\n`-- README.md\n\n2 directories, 2 files\n"
+	if buf.String() != want {
+		t.Fatalf("unexpected ascii text\n%s", buf.String())
+	}
+}
+
+func TestRenderTextSizeAndColor(t *testing.T) {
+	tr := New[FileInfo]()
+	tr.Insert("bin/run", FileInfo{Size: 1536, Mode: 0755})
+	tr.Insert("data.bin", FileInfo{Size: 3 << 30})
+	aggregate(tr.Root())
+	nodes := ToFileTreeNodesWithInfo(tr)
+	nodes[1].Status = StatusAdded
+
+	var buf bytes.Buffer
+	RenderText(&buf, nodes, &RenderOptions{ShowSize: true, Color: true})
+	out := buf.String()
+	for _, want := range []string{
+		"[ 1.5K]  " + ansiDir + "bin" + ansiReset,
+		"[ 1.5K]  " + ansiExec + "run" + ansiReset,
+		"[ 3.0G]  " + ansiAdded + "data.bin" + ansiReset + " (added)",
+	} {
+		if !strings.Contains(out, want) {
+			t.Fatalf("%q not found in\n%s", want, out)
+		}
+	}
+	if FormatSize(512) != "512" || FormatSize(1<<20) != "1.0M" {
+		t.Fatal("unexpected size format")
+	}
+}
+
+func TestRenderMarkdown(t *testing.T) {
+	var buf bytes.Buffer
+	RenderMarkdown(&buf, renderTree(), nil)
+	want := "- **src/**\n  - **lib/**\n    - a\\_b.go\n  - main.go\n- README.md\n"
+	if buf.String() != want {
+		t.Fatalf("unexpected markdown\n%s", buf.String())
+	}
+}
+
+func TestRenderHTML(t *testing.T) {
+	nodes := renderTree()
+	nodes[1].Title = "<README>"
+	var buf bytes.Buffer
+	RenderHTML(&buf, nodes, &RenderOptions{MaxDepth: 1})
+	want := `<ul class="file-tree">
+  <li class="dir" data-key="src">src</li>
+  <li class="file" data-key="README.md">&lt;README&gt;</li>
+</ul>
+`
+	if buf.String() != want {
+		t.Fatalf("unexpected html\n%s", buf.String())
+	}
+
+	buf.Reset()
+	RenderHTML(&buf, ToFileTreeNodes(must(BuildTree([]string{"a/b"}))), nil)
+	want = `<ul class="file-tree">
+  <li class="dir" data-key="a">a
+  <ul>
+    <li class="file" data-key="a/b">b</li>
+  </ul>
+  </li>
+</ul>
+`
+	if buf.String() != want {
+		t.Fatalf("unexpected nested html\n%s", buf.String())
+	}
+}
@@ -0,0 +1,140 @@
+package tree
+
+import "strings"
+
+// MatchFunc reports whether the node is matched by a search
+type MatchFunc func(node *FileTreeNode) bool
+
+// MatchSubstring matches the nodes whose title contains the substring case-insensitively
+func MatchSubstring(substr string) MatchFunc {
+	substr = strings.ToLower(substr)
+	return func(node *FileTreeNode) bool {
+		return strings.Contains(strings.ToLower(node.Title), substr)
+	}
+}
+
+// MatchGlob matches the nodes by the .gitignore-style glob, the pattern without a slash
+// matches the title and the pattern with a slash matches the key.
+func MatchGlob(pattern string) (MatchFunc, error) {
+	rules, err := NewIgnoreRules(pattern)
+	if err != nil {
+		return nil, err
+	}
+	return func(node *FileTreeNode) bool {
+		return rules.Match(node.Key, !node.IsLeaf)
+	}, nil
+}
+
+// FilterFileTree returns a copy of the tree with the matched nodes and their ancestors,
+// the children of a matched directory are kept only when they are matched too.
+func FilterFileTree(nodes FileTreeNodes, match MatchFunc) FileTreeNodes {
+	result := make(FileTreeNodes, 0)
+	for i := range nodes {
+		node := nodes[i]
+		var children FileTreeNodes
+		if node.Children != nil {
+			children = FilterFileTree(*node.Children, match)
+		}
+		if len(children) == 0 && !match(&node) {
+			continue
+		}
+		if node.Children != nil {
+			node.Children = &children
+		}
+		result.Append(node)
+	}
+	return result
+}
+
+// ExpandedKeys returns the keys of the directories to expand to show all the matched nodes,
+// which can be used as the expandedKeys of the antd tree.
+func ExpandedKeys(nodes FileTreeNodes, match MatchFunc) []string {
+	keys := make([]string, 0)
+	expandedKeys(nodes, match, &keys)
+	return keys
+}
+
+func expandedKeys(nodes FileTreeNodes, match MatchFunc, keys *[]string) (found bool) {
+	for i := range nodes {
+		node := &nodes[i]
+		if node.Children != nil {
+			// reserve the position so the ancestor is before its descendants, drop it when nothing matched
+			pos := len(*keys)
+			*keys = append(*keys, node.Key)
+			if expandedKeys(*node.Children, match, keys) {
+				found = true
+			} else {
+				*keys = append((*keys)[:pos], (*keys)[pos+1:]...)
+			}
+		}
+		if match(node) {
+			found = true
+		}
+	}
+	return
+}
+
+// FindFileTreeNode returns the node by key
+func FindFileTreeNode(nodes FileTreeNodes, key string) (*FileTreeNode, bool) {
+	for i := range nodes {
+		node := &nodes[i]
+		if node.Key == key {
+			return node, true
+		}
+		if node.Children != nil && strings.HasPrefix(key, node.Key+"/") {
+			return FindFileTreeNode(*node.Children, key)
+		}
+	}
+	return nil, false
+}
+
+// Page is a page of the children of a directory
+type Page struct {
+	Nodes   FileTreeNodes `json:"nodes"`
+	Offset  int           `json:"offset"`
+	Total   int           `json:"total"`
+	HasMore bool          `json:"hasMore"`
+}
+
+// Paginate returns the nodes in [offset, offset+limit), all the nodes after offset are returned when limit is not positive
+func Paginate(nodes FileTreeNodes, offset, limit int) Page {
+	total := len(nodes)
+	offset = min(max(offset, 0), total)
+	end := total
+	if limit > 0 {
+		end = min(offset+limit, total)
+	}
+	return Page{
+		Nodes:   nodes[offset:end],
+		Offset:  offset,
+		Total:   total,
+		HasMore: end < total,
+	}
+}
+
+// LoadChildren returns a page of the direct children of the directory for lazy loading,
+// the empty key returns the top level nodes. The returned nodes have no children, the
+// directories are loaded again by their keys when expanded.
+func LoadChildren(nodes FileTreeNodes, key string, offset, limit int) (Page, error) {
+	if key != "" {
+		parent, exists := FindFileTreeNode(nodes, key)
+		if !exists {
+			return Page{}, ErrNotFound
+		}
+		if parent.IsLeaf {
+			return Page{}, ErrNotDir
+		}
+		nodes = nil
+		if parent.Children != nil {
+			nodes = *parent.Children
+		}
+	}
+	page := Paginate(nodes, offset, limit)
+	shallow := make(FileTreeNodes, len(page.Nodes))
+	for i, node := range page.Nodes {
+		node.Children = nil
+		shallow[i] = node
+	}
+	page.Nodes = shallow
+	return page, nil
+}
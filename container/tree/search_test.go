@@ -0,0 +1,105 @@
+package tree
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"testing"
+)
+
+func searchTree() FileTreeNodes {
+	return ToFileTreeNodes(must(BuildTree([]string{
+		"src/app/main.go",
+		"src/app/Config.yaml",
+		"src/lib/util.go",
+		"docs/config.md",
+		"docs/guide/intro.md",
+		"config.yaml",
+	})))
+}
+
+func must[T any](v T, err error) T {
+	if err != nil {
+		panic(err)
+	}
+	return v
+}
+
+func TestFilterFileTree(t *testing.T) {
+	nodes := FilterFileTree(searchTree(), MatchSubstring("CONFIG"))
+	want := []string{"docs", "docs/config.md", "src", "src/app", "src/app/Config.yaml", "config.yaml"}
+	if got := nodeKeys(nodes); !slices.Equal(got, want) {
+		t.Fatalf("unexpected keys %v", got)
+	}
+
+	match, err := MatchGlob("src/**/*.go")
+	if err != nil {
+		t.Fatal(err)
+	}
+	nodes = FilterFileTree(searchTree(), match)
+	want = []string{"src", "src/app", "src/app/main.go", "src/lib", "src/lib/util.go"}
+	if got := nodeKeys(nodes); !slices.Equal(got, want) {
+		t.Fatalf("unexpected keys %v", got)
+	}
+
+	if nodes := FilterFileTree(searchTree(), MatchSubstring("missing")); len(nodes) != 0 {
+		t.Fatalf("nothing should be matched, got %v", nodeKeys(nodes))
+	}
+}
+
+func TestExpandedKeys(t *testing.T) {
+	keys := ExpandedKeys(searchTree(), MatchSubstring("intro"))
+	if !slices.Equal(keys, []string{"docs", "docs/guide"}) {
+		t.Fatalf("unexpected keys %v", keys)
+	}
+	keys = ExpandedKeys(searchTree(), MatchSubstring(".go"))
+	if !slices.Equal(keys, []string{"src", "src/app", "src/lib"}) {
+		t.Fatalf("unexpected keys %v", keys)
+	}
+}
+
+func TestLoadChildren(t *testing.T) {
+	page, err := LoadChildren(searchTree(), "", 0, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := nodeKeys(page.Nodes); !slices.Equal(got, []string{"docs", "src", "config.yaml"}) {
+		t.Fatalf("unexpected top level %v", got)
+	}
+	if page.Nodes[0].Children != nil || page.Nodes[0].IsLeaf {
+		t.Fatal("directories should be returned without children for lazy loading")
+	}
+
+	page, err = LoadChildren(searchTree(), "src/app", 1, 5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := nodeKeys(page.Nodes); !slices.Equal(got, []string{"src/app/main.go"}) || page.Total != 2 || page.HasMore {
+		t.Fatalf("unexpected page %+v", page)
+	}
+	if _, err = LoadChildren(searchTree(), "src/missing", 0, 0); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if _, err = LoadChildren(searchTree(), "config.yaml", 0, 0); !errors.Is(err, ErrNotDir) {
+		t.Fatalf("unexpected error %v", err)
+	}
+}
+
+func TestPaginate(t *testing.T) {
+	paths := make([]string, 25)
+	for i := range paths {
+		paths[i] = fmt.Sprintf("wide/f%d", i)
+	}
+	wide := *CreateFileTreeLayout(paths)[0].Children
+	page := Paginate(wide, 20, 10)
+	if len(page.Nodes) != 5 || page.Total != 25 || page.HasMore || page.Nodes[0].Title != "f20" {
+		t.Fatalf("unexpected last page %+v", page)
+	}
+	page = Paginate(wide, 0, 10)
+	if len(page.Nodes) != 10 || !page.HasMore {
+		t.Fatalf("unexpected first page %+v", page)
+	}
+	if page = Paginate(wide, 100, 10); len(page.Nodes) != 0 || page.Offset != 25 {
+		t.Fatalf("unexpected page out of range %+v", page)
+	}
+}
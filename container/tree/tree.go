@@ -0,0 +1,349 @@
+// Package tree provides a generic path tree and the antd-style file tree layout built on it.
+package tree
+
+import (
+	"errors"
+	"iter"
+	"path"
+	"slices"
+	"strings"
+)
+
+var (
+	ErrInvalidPath = errors.New("tree: invalid path")
+	ErrNotFound    = errors.New("tree: node not found")
+	ErrExists      = errors.New("tree: node already exists")
+	ErrNotDir      = errors.New("tree: parent is not a directory")
+	ErrInvalidMove = errors.New("tree: can not move a node into itself")
+)
+
+// SkipChildren is returned by the WalkFunc to skip the children of the node
+var SkipChildren = errors.New("tree: skip children")
+
+// WalkFunc is called for each node visited by Walk and WalkBFS, depth of the top level nodes is 0
+type WalkFunc[T any] func(node *Node[T], depth int) error
+
+// Node is a file or a directory in the tree carrying a payload
+type Node[T any] struct {
+	Data T
+
+	name     string
+	dir      bool
+	parent   *Node[T]
+	children map[string]*Node[T]
+	sorted   []*Node[T] // sorted children cache, nil when dirty
+}
+
+// Name returns the last element of the path
+func (n *Node[T]) Name() string {
+	return n.name
+}
+
+// Key returns the slash separated path from the root, the root key is empty
+func (n *Node[T]) Key() string {
+	if n.parent == nil {
+		return ""
+	}
+	if parentKey := n.parent.Key(); parentKey != "" {
+		return parentKey + "/" + n.name
+	}
+	return n.name
+}
+
+// IsDir returns true when the node is a directory
+func (n *Node[T]) IsDir() bool {
+	return n.dir
+}
+
+// IsRoot returns true when the node is the root
+func (n *Node[T]) IsRoot() bool {
+	return n.parent == nil
+}
+
+// Parent returns the parent, nil for the root
+func (n *Node[T]) Parent() *Node[T] {
+	return n.parent
+}
+
+// Depth returns the depth of the node, 0 for the top level nodes and -1 for the root
+func (n *Node[T]) Depth() int {
+	depth := -1
+	for p := n.parent; p != nil; p = p.parent {
+		depth++
+	}
+	return depth
+}
+
+// Child returns the direct child by name
+func (n *Node[T]) Child(name string) (*Node[T], bool) {
+	child, exists := n.children[name]
+	return child, exists
+}
+
+// Len returns the number of direct children
+func (n *Node[T]) Len() int {
+	return len(n.children)
+}
+
+// Children returns the direct children, directories first and then in natural order by name.
+// The returned slice is shared, do not modify it.
+func (n *Node[T]) Children() []*Node[T] {
+	if n.sorted == nil && len(n.children) > 0 {
+		n.sorted = make([]*Node[T], 0, len(n.children))
+		for _, child := range n.children {
+			n.sorted = append(n.sorted, child)
+		}
+		slices.SortFunc(n.sorted, compareNodes[T])
+	}
+	return n.sorted
+}
+
+func compareNodes[T any](a, b *Node[T]) int {
+	if a.dir != b.dir {
+		if a.dir {
+			return -1
+		}
+		return 1
+	}
+	return CompareNatural(a.name, b.name)
+}
+
+func (n *Node[T]) addChild(child *Node[T]) {
+	if n.children == nil {
+		n.children = make(map[string]*Node[T])
+	}
+	child.parent = n
+	n.children[child.name] = child
+	n.sorted = nil
+}
+
+func (n *Node[T]) removeChild(child *Node[T]) {
+	delete(n.children, child.name)
+	child.parent = nil
+	n.sorted = nil
+}
+
+// Tree is a tree of slash separated paths with O(1) child lookup, it is not routine-safe
+type Tree[T any] struct {
+	root *Node[T]
+	size int
+}
+
+// New returns an empty tree
+func New[T any]() *Tree[T] {
+	return &Tree[T]{root: &Node[T]{dir: true}}
+}
+
+// Root returns the root directory, which has no name
+func (t *Tree[T]) Root() *Node[T] {
+	return t.root
+}
+
+// Len returns the number of nodes excluding the root
+func (t *Tree[T]) Len() int {
+	return t.size
+}
+
+// SplitPath cleans the slash separated path and returns its elements, the root has no elements
+func SplitPath(p string) []string {
+	p = path.Clean("/" + p)
+	if p == "/" {
+		return nil
+	}
+	return strings.Split(p[1:], "/")
+}
+
+// Insert adds a file and creates the missing parent directories, the data of the existing file is replaced
+func (t *Tree[T]) Insert(p string, data T) (*Node[T], error) {
+	return t.insert(p, data, false)
+}
+
+// InsertDir adds a directory and creates the missing parent directories, the data of the existing directory is replaced
+func (t *Tree[T]) InsertDir(p string, data T) (*Node[T], error) {
+	return t.insert(p, data, true)
+}
+
+func (t *Tree[T]) insert(p string, data T, dir bool) (*Node[T], error) {
+	names := SplitPath(p)
+	if len(names) == 0 {
+		return nil, ErrInvalidPath
+	}
+	parent, err := t.mkdirAll(names[:len(names)-1])
+	if err != nil {
+		return nil, err
+	}
+	name := names[len(names)-1]
+	if node, exists := parent.children[name]; exists {
+		if node.dir != dir {
+			return nil, ErrExists
+		}
+		node.Data = data
+		return node, nil
+	}
+	node := &Node[T]{Data: data, name: name, dir: dir}
+	parent.addChild(node)
+	t.size++
+	return node, nil
+}
+
+func (t *Tree[T]) mkdirAll(names []string) (*Node[T], error) {
+	current := t.root
+	for _, name := range names {
+		child, exists := current.children[name]
+		if !exists {
+			child = &Node[T]{name: name, dir: true}
+			current.addChild(child)
+			t.size++
+		} else if !child.dir {
+			return nil, ErrNotDir
+		}
+		current = child
+	}
+	return current, nil
+}
+
+// Find returns the node by its key, the empty key returns the root
+func (t *Tree[T]) Find(key string) (*Node[T], bool) {
+	current := t.root
+	for _, name := range SplitPath(key) {
+		child, exists := current.children[name]
+		if !exists {
+			return nil, false
+		}
+		current = child
+	}
+	return current, true
+}
+
+// Delete removes the node and all its descendants, it returns false when the node is not found
+func (t *Tree[T]) Delete(key string) bool {
+	node, exists := t.Find(key)
+	if !exists || node.IsRoot() {
+		return false
+	}
+	t.size -= countNodes(node)
+	node.parent.removeChild(node)
+	return true
+}
+
+func countNodes[T any](node *Node[T]) int {
+	count := 1
+	for _, child := range node.children {
+		count += countNodes(child)
+	}
+	return count
+}
+
+// Move moves the node with its descendants to the new key, the missing parent directories are created
+func (t *Tree[T]) Move(from, to string) error {
+	node, exists := t.Find(from)
+	if !exists {
+		return ErrNotFound
+	}
+	names := SplitPath(to)
+	if node.IsRoot() || len(names) == 0 {
+		return ErrInvalidPath
+	}
+	if _, exists := t.Find(to); exists {
+		return ErrExists
+	}
+	// the target parent must not be inside the moved node
+	if fromKey := node.Key(); strings.HasPrefix(strings.Join(names, "/")+"/", fromKey+"/") {
+		return ErrInvalidMove
+	}
+	parent, err := t.mkdirAll(names[:len(names)-1])
+	if err != nil {
+		return err
+	}
+	node.parent.removeChild(node)
+	node.name = names[len(names)-1]
+	parent.addChild(node)
+	return nil
+}
+
+// Walk visits the nodes depth-first in pre-order, children in sorted order, the root is not visited.
+// When fn returns SkipChildren, the children of the node are skipped, other errors stop the walk.
+func (t *Tree[T]) Walk(fn WalkFunc[T]) error {
+	err := walk(t.root, -1, fn)
+	if errors.Is(err, SkipChildren) {
+		return nil
+	}
+	return err
+}
+
+func walk[T any](node *Node[T], depth int, fn WalkFunc[T]) error {
+	for _, child := range node.Children() {
+		err := fn(child, depth+1)
+		if errors.Is(err, SkipChildren) {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		if err = walk(child, depth+1, fn); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// WalkBFS visits the nodes breadth-first level by level, children in sorted order, the root is not visited.
+// When fn returns SkipChildren, the children of the node are skipped, other errors stop the walk.
+func (t *Tree[T]) WalkBFS(fn WalkFunc[T]) error {
+	level := t.root.Children()
+	for depth := 0; len(level) > 0; depth++ {
+		var next []*Node[T]
+		for _, node := range level {
+			err := fn(node, depth)
+			if errors.Is(err, SkipChildren) {
+				continue
+			}
+			if err != nil {
+				return err
+			}
+			next = append(next, node.Children()...)
+		}
+		level = next
+	}
+	return nil
+}
+
+// All returns an iterator over the nodes in the depth-first order of Walk
+func (t *Tree[T]) All() iter.Seq[*Node[T]] {
+	return func(yield func(*Node[T]) bool) {
+		errStop := errors.New("stop")
+		t.Walk(func(node *Node[T], _ int) error {
+			if !yield(node) {
+				return errStop
+			}
+			return nil
+		})
+	}
+}
+
+// PruneEmptyDirs removes the directories which have no files inside recursively,
+// and returns the number of removed directories.
+func (t *Tree[T]) PruneEmptyDirs() int {
+	return t.pruneEmptyDirs(0)
+}
+
+// pruneEmptyDirs keeps the directories at maxDepth, whose children may be not loaded
+func (t *Tree[T]) pruneEmptyDirs(maxDepth int) int {
+	removed := pruneEmptyDirs(t.root, 0, maxDepth)
+	t.size -= removed
+	return removed
+}
+
+func pruneEmptyDirs[T any](node *Node[T], depth, maxDepth int) (removed int) {
+	for _, child := range node.children {
+		if !child.dir || (maxDepth > 0 && depth+1 >= maxDepth) {
+			continue
+		}
+		removed += pruneEmptyDirs(child, depth+1, maxDepth)
+		if len(child.children) == 0 {
+			node.removeChild(child)
+			removed++
+		}
+	}
+	return
+}
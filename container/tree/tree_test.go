@@ -0,0 +1,130 @@
+package tree
+
+import (
+	"errors"
+	"slices"
+	"testing"
+)
+
+func keys[T any](t *Tree[T]) []string {
+	var result []string
+	for node := range t.All() {
+		result = append(result, node.Key())
+	}
+	return result
+}
+
+func TestCompareNatural(t *testing.T) {
+	names := []string{"file10", "File2", "file1", "a", "file01", "B", "file2", "file1a"}
+	slices.SortFunc(names, CompareNatural)
+	want := []string{"a", "B", "file1", "file01", "file1a", "File2", "file2", "file10"}
+	if !slices.Equal(names, want) {
+		t.Fatalf("unexpected order %v", names)
+	}
+}
+
+func TestTreeInsertAndSort(t *testing.T) {
+	tr := New[int]()
+	for i, p := range []string{"src/main.go", "README.md", "src/util/b10.go", "src/util/b9.go", "/LICENSE"} {
+		if _, err := tr.Insert(p, i); err != nil {
+			t.Fatal(err)
+		}
+	}
+	tr.InsertDir("docs", 0)
+	want := []string{"docs", "src", "src/util", "src/util/b9.go", "src/util/b10.go", "src/main.go", "LICENSE", "README.md"}
+	if got := keys(tr); !slices.Equal(got, want) {
+		t.Fatalf("unexpected order %v", got)
+	}
+	if tr.Len() != 8 {
+		t.Fatalf("unexpected len %d", tr.Len())
+	}
+	node, ok := tr.Find("src/util/b9.go")
+	if !ok || node.Data != 3 || node.Depth() != 2 || node.Parent().Name() != "util" {
+		t.Fatalf("unexpected node %+v", node)
+	}
+	if _, err := tr.Insert("src/main.go/x", 0); !errors.Is(err, ErrNotDir) {
+		t.Fatalf("file can not have children, got %v", err)
+	}
+	if _, err := tr.InsertDir("src/main.go", 0); !errors.Is(err, ErrExists) {
+		t.Fatalf("file can not be replaced by dir, got %v", err)
+	}
+	if _, err := tr.Insert("/", 0); !errors.Is(err, ErrInvalidPath) {
+		t.Fatalf("root can not be inserted, got %v", err)
+	}
+	tr.Insert("src/main.go", 100)
+	if node, _ := tr.Find("src/main.go"); node.Data != 100 || tr.Len() != 8 {
+		t.Fatal("insert should replace the data")
+	}
+}
+
+func TestTreeDeleteMovePrune(t *testing.T) {
+	tr, err := BuildTree([]string{"a/b/c.txt", "a/b/d.txt", "a/e.txt", "f.txt"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	tr.InsertDir("empty/nested", struct{}{})
+	if !tr.Delete("a/b") || tr.Delete("a/b") {
+		t.Fatal("delete should succeed only once")
+	}
+	if tr.Len() != 5 {
+		t.Fatalf("unexpected len %d", tr.Len())
+	}
+	if err := tr.Move("a", "a/x"); !errors.Is(err, ErrInvalidMove) {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if err := tr.Move("a", "f.txt"); !errors.Is(err, ErrExists) {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if err := tr.Move("missing", "x"); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if err := tr.Move("a/e.txt", "g/h/e2.txt"); err != nil {
+		t.Fatal(err)
+	}
+	if n := tr.PruneEmptyDirs(); n != 3 {
+		t.Fatalf("a, empty and empty/nested should be pruned, pruned %d", n)
+	}
+	want := []string{"g", "g/h", "g/h/e2.txt", "f.txt"}
+	if got := keys(tr); !slices.Equal(got, want) || tr.Len() != len(want) {
+		t.Fatalf("unexpected tree %v, len %d", got, tr.Len())
+	}
+}
+
+func TestTreeWalk(t *testing.T) {
+	tr, _ := BuildTree([]string{"a/b/c", "a/d", "e/f", "g"})
+	var dfs []string
+	tr.Walk(func(node *Node[struct{}], depth int) error {
+		dfs = append(dfs, node.Key())
+		if node.Key() == "a/b" {
+			return SkipChildren
+		}
+		return nil
+	})
+	if want := []string{"a", "a/b", "a/d", "e", "e/f", "g"}; !slices.Equal(dfs, want) {
+		t.Fatalf("unexpected dfs %v", dfs)
+	}
+
+	var bfs []string
+	var depths []int
+	tr.WalkBFS(func(node *Node[struct{}], depth int) error {
+		bfs = append(bfs, node.Key())
+		depths = append(depths, depth)
+		return nil
+	})
+	if want := []string{"a", "e", "g", "a/b", "a/d", "e/f", "a/b/c"}; !slices.Equal(bfs, want) {
+		t.Fatalf("unexpected bfs %v", bfs)
+	}
+	if want := []int{0, 0, 0, 1, 1, 1, 2}; !slices.Equal(depths, want) {
+		t.Fatalf("unexpected depths %v", depths)
+	}
+
+	errStop := errors.New("stop")
+	var visited int
+	err := tr.Walk(func(node *Node[struct{}], depth int) error {
+		visited++
+		return errStop
+	})
+	if !errors.Is(err, errStop) || visited != 1 {
+		t.Fatal("walk should stop on error")
+	}
+}
@@ -0,0 +1,271 @@
+package crypto
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"fmt"
+	"strings"
+
+	"golang.org/x/crypto/chacha20poly1305"
+)
+
+// EnvelopeVersion is the current version of the sealed envelope format
+const EnvelopeVersion byte = 1
+
+// maxKeyIDLen is the max length of the key id, which is stored in one byte
+const maxKeyIDLen = 255
+
+// Algorithm is the AEAD cipher used to seal the envelope
+type Algorithm byte
+
+const (
+	// AES256GCM stands for AES-256 in GCM mode
+	AES256GCM Algorithm = 1
+	// ChaCha20Poly1305 stands for ChaCha20-Poly1305 defined in RFC 8439
+	ChaCha20Poly1305 Algorithm = 2
+)
+
+// String returns the name of the algorithm
+func (a Algorithm) String() string {
+	switch a {
+	case AES256GCM:
+		return "AES-256-GCM"
+	case ChaCha20Poly1305:
+		return "ChaCha20-Poly1305"
+	default:
+		return fmt.Sprintf("Algorithm(%d)", byte(a))
+	}
+}
+
+// MarshalText implements encoding.TextMarshaler
+func (a Algorithm) MarshalText() ([]byte, error) {
+	switch a {
+	case AES256GCM, ChaCha20Poly1305:
+		return []byte(a.String()), nil
+	default:
+		return nil, ErrUnsupportedAlgorithm
+	}
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler
+func (a *Algorithm) UnmarshalText(text []byte) (err error) {
+	*a, err = ParseAlgorithm(string(text))
+	return
+}
+
+// ParseAlgorithm parses the algorithm name, the name is case-insensitive
+func ParseAlgorithm(name string) (Algorithm, error) {
+	switch strings.ToUpper(name) {
+	case "AES-256-GCM", "AES256GCM", "AES-GCM":
+		return AES256GCM, nil
+	case "CHACHA20-POLY1305", "CHACHA20POLY1305":
+		return ChaCha20Poly1305, nil
+	default:
+		return 0, ErrUnsupportedAlgorithm
+	}
+}
+
+// KeySize returns the key length required by the algorithm
+func (a Algorithm) KeySize() int {
+	switch a {
+	case AES256GCM:
+		return 32
+	case ChaCha20Poly1305:
+		return chacha20poly1305.KeySize
+	default:
+		return 0
+	}
+}
+
+func newAEAD(alg Algorithm, key []byte) (aead cipher.AEAD, err error) {
+	switch alg {
+	case AES256GCM, ChaCha20Poly1305:
+	default:
+		err = ErrUnsupportedAlgorithm
+		return
+	}
+	if len(key) != alg.KeySize() {
+		err = ErrKeySize
+		return
+	}
+	if alg == ChaCha20Poly1305 {
+		return chacha20poly1305.New(key)
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return
+	}
+	return cipher.NewGCM(block)
+}
+
+// Envelope is the self-describing output of the sealed box.
+//
+// The binary layout is:
+//
+//	version(1) | algorithm(1) | keyIDLen(1) | keyID | nonce | ciphertext | tag
+//
+// The header before the nonce is authenticated together with the associated data,
+// so neither the algorithm nor the key id can be swapped without detection.
+type Envelope struct {
+	Version    byte
+	Algorithm  Algorithm
+	KeyID      string
+	Nonce      []byte
+	Ciphertext []byte // ciphertext with the tag appended
+}
+
+func (e *Envelope) header() []byte {
+	header := make([]byte, 0, 3+len(e.KeyID))
+	header = append(header, e.Version, byte(e.Algorithm), byte(len(e.KeyID)))
+	return append(header, e.KeyID...)
+}
+
+// Marshal encodes the envelope into bytes
+func (e *Envelope) Marshal() []byte {
+	header := e.header()
+	data := make([]byte, 0, len(header)+len(e.Nonce)+len(e.Ciphertext))
+	data = append(data, header...)
+	data = append(data, e.Nonce...)
+	return append(data, e.Ciphertext...)
+}
+
+// ParseEnvelope decodes the envelope from bytes without decrypting it,
+// which is useful to find out the key id before opening.
+func ParseEnvelope(data []byte) (env *Envelope, err error) {
+	if len(data) < 3 {
+		err = ErrInvalidEnvelope
+		return
+	}
+	if data[0] != EnvelopeVersion {
+		err = ErrUnsupportedVersion
+		return
+	}
+	alg := Algorithm(data[1])
+	var nonceSize, overhead int
+	switch alg {
+	case AES256GCM:
+		nonceSize, overhead = 12, 16
+	case ChaCha20Poly1305:
+		nonceSize, overhead = chacha20poly1305.NonceSize, chacha20poly1305.Overhead
+	default:
+		err = ErrUnsupportedAlgorithm
+		return
+	}
+	keyIDLen := int(data[2])
+	body := data[3:]
+	if len(body) < keyIDLen+nonceSize+overhead {
+		err = ErrInvalidEnvelope
+		return
+	}
+	env = &Envelope{
+		Version:    data[0],
+		Algorithm:  alg,
+		KeyID:      string(body[:keyIDLen]),
+		Nonce:      body[keyIDLen : keyIDLen+nonceSize],
+		Ciphertext: body[keyIDLen+nonceSize:],
+	}
+	return
+}
+
+// SealedBox encrypts and decrypts envelopes with one key, it is routine-safe
+type SealedBox struct {
+	alg   Algorithm
+	keyID string
+	aead  cipher.AEAD
+}
+
+// NewSealedBox creates a sealed box with the algorithm and the identified key
+func NewSealedBox(alg Algorithm, keyID string, key []byte) (box *SealedBox, err error) {
+	if len(keyID) > maxKeyIDLen {
+		err = ErrKeyIDTooLong
+		return
+	}
+	aead, err := newAEAD(alg, key)
+	if err != nil {
+		return
+	}
+	box = &SealedBox{
+		alg:   alg,
+		keyID: keyID,
+		aead:  aead,
+	}
+	return
+}
+
+// Algorithm returns the algorithm of the sealed box
+func (b *SealedBox) Algorithm() Algorithm {
+	return b.alg
+}
+
+// KeyID returns the key id of the sealed box
+func (b *SealedBox) KeyID() string {
+	return b.keyID
+}
+
+// Seal encrypts the plaintext with a random nonce and returns the encoded envelope
+func (b *SealedBox) Seal(plaintext, additionalData []byte) ([]byte, error) {
+	nonce := make([]byte, b.aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, fmt.Errorf("crypto: generate nonce error, %s", err.Error())
+	}
+	env := &Envelope{
+		Version:   EnvelopeVersion,
+		Algorithm: b.alg,
+		KeyID:     b.keyID,
+		Nonce:     nonce,
+	}
+	env.Ciphertext = b.aead.Seal(nil, nonce, plaintext, authData(env, additionalData))
+	return env.Marshal(), nil
+}
+
+// Open parses the envelope and decrypts it
+func (b *SealedBox) Open(data, additionalData []byte) ([]byte, error) {
+	env, err := ParseEnvelope(data)
+	if err != nil {
+		return nil, err
+	}
+	return b.OpenEnvelope(env, additionalData)
+}
+
+// OpenEnvelope decrypts the parsed envelope
+func (b *SealedBox) OpenEnvelope(env *Envelope, additionalData []byte) ([]byte, error) {
+	if env.Algorithm != b.alg {
+		return nil, ErrUnsupportedAlgorithm
+	}
+	if env.KeyID != b.keyID {
+		return nil, ErrKeyIDMismatch
+	}
+	plaintext, err := b.aead.Open(nil, env.Nonce, env.Ciphertext, authData(env, additionalData))
+	if err != nil {
+		return nil, ErrAuthFailed
+	}
+	return plaintext, nil
+}
+
+// authData binds the envelope header to the associated data
+func authData(env *Envelope, additionalData []byte) []byte {
+	return append(env.header(), additionalData...)
+}
+
+// Seal encrypts the plaintext with the key and returns the encoded envelope
+func Seal(alg Algorithm, keyID string, key, plaintext, additionalData []byte) ([]byte, error) {
+	box, err := NewSealedBox(alg, keyID, key)
+	if err != nil {
+		return nil, err
+	}
+	return box.Seal(plaintext, additionalData)
+}
+
+// Open decrypts the envelope with the key, the algorithm and key id are read from the envelope
+func Open(key, data, additionalData []byte) ([]byte, error) {
+	env, err := ParseEnvelope(data)
+	if err != nil {
+		return nil, err
+	}
+	box, err := NewSealedBox(env.Algorithm, env.KeyID, key)
+	if err != nil {
+		return nil, err
+	}
+	return box.OpenEnvelope(env, additionalData)
+}
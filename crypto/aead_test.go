@@ -0,0 +1,98 @@
+package crypto
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+)
+
+var (
+	aeadKey      = []byte("aaaabbbbccccddddaaaabbbbccccdddd")
+	aeadSamples  = []string{"", "hello", "golang programming"}
+	aeadAlgs     = []Algorithm{AES256GCM, ChaCha20Poly1305}
+	aeadAuthData = []byte("user:1001")
+)
+
+func TestSealAndOpen(t *testing.T) {
+	for _, alg := range aeadAlgs {
+		for _, sample := range aeadSamples {
+			sealed, err := Seal(alg, "v1", aeadKey, []byte(sample), aeadAuthData)
+			if err != nil {
+				t.Fatal(err)
+			}
+			opened, err := Open(aeadKey, sealed, aeadAuthData)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(opened) != sample {
+				t.Fatalf("invalid %s algorithm in sealed box", alg)
+			}
+		}
+	}
+}
+
+func TestSealRandomNonce(t *testing.T) {
+	box, err := NewSealedBox(AES256GCM, "v1", aeadKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	first, _ := box.Seal([]byte("hello"), nil)
+	second, _ := box.Seal([]byte("hello"), nil)
+	if bytes.Equal(first, second) {
+		t.Fatal("identical plaintexts should produce different envelopes")
+	}
+}
+
+func TestParseEnvelope(t *testing.T) {
+	sealed, err := Seal(ChaCha20Poly1305, "2024-01", aeadKey, []byte("hello"), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	env, err := ParseEnvelope(sealed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if env.Version != EnvelopeVersion || env.Algorithm != ChaCha20Poly1305 || env.KeyID != "2024-01" {
+		t.Fatalf("unexpected envelope header, %+v", env)
+	}
+	if !bytes.Equal(env.Marshal(), sealed) {
+		t.Fatal("envelope should marshal back to the same bytes")
+	}
+}
+
+func TestOpenTampered(t *testing.T) {
+	for _, alg := range aeadAlgs {
+		sealed, _ := Seal(alg, "v1", aeadKey, []byte("hello"), aeadAuthData)
+		for i := 3; i < len(sealed); i++ {
+			tampered := append([]byte(nil), sealed...)
+			tampered[i] ^= 0x01
+			if _, err := Open(aeadKey, tampered, aeadAuthData); err == nil {
+				t.Fatalf("tampered byte %d should be detected", i)
+			}
+		}
+		if _, err := Open(aeadKey, sealed, []byte("user:1002")); !errors.Is(err, ErrAuthFailed) {
+			t.Fatalf("mismatched associated data should fail, got %v", err)
+		}
+	}
+}
+
+func TestOpenErrors(t *testing.T) {
+	if _, err := NewSealedBox(AES256GCM, "v1", []byte("short")); !errors.Is(err, ErrKeySize) {
+		t.Fatalf("expect ErrKeySize, got %v", err)
+	}
+	if _, err := NewSealedBox(Algorithm(9), "v1", aeadKey); !errors.Is(err, ErrUnsupportedAlgorithm) {
+		t.Fatalf("expect ErrUnsupportedAlgorithm, got %v", err)
+	}
+	if _, err := ParseEnvelope([]byte{EnvelopeVersion, byte(AES256GCM), 0}); !errors.Is(err, ErrInvalidEnvelope) {
+		t.Fatalf("expect ErrInvalidEnvelope, got %v", err)
+	}
+	if _, err := ParseEnvelope([]byte{9, byte(AES256GCM), 0}); !errors.Is(err, ErrUnsupportedVersion) {
+		t.Fatalf("expect ErrUnsupportedVersion, got %v", err)
+	}
+
+	sealed, _ := Seal(AES256GCM, "v1", aeadKey, []byte("hello"), nil)
+	box, _ := NewSealedBox(AES256GCM, "v2", aeadKey)
+	if _, err := box.Open(sealed, nil); !errors.Is(err, ErrKeyIDMismatch) {
+		t.Fatalf("expect ErrKeyIDMismatch, got %v", err)
+	}
+}
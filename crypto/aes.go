@@ -4,47 +4,124 @@ import (
 	"bytes"
 	"crypto/aes"
 	"crypto/cipher"
+	"crypto/rand"
+	"fmt"
 )
 
-// AESEncrypt - AES encryption
+// AESEncrypt - AES encryption, the key prefix is used as the iv for compatibility.
+// Use AESEncryptRandomIV for new data.
 func AESEncrypt(origData, key []byte) ([]byte, error) {
-	block, err := aes.NewCipher(key)
+	if !validAESKeySize(key) {
+		return nil, ErrKeySize
+	}
+	return AESEncryptWithIV(origData, key, key[:aes.BlockSize])
+}
+
+// AESDecrypt - AES decryption, the key prefix is used as the iv for compatibility
+func AESDecrypt(cryptedData, key []byte) ([]byte, error) {
+	if !validAESKeySize(key) {
+		return nil, ErrKeySize
+	}
+	return AESDecryptWithIV(cryptedData, key, key[:aes.BlockSize])
+}
+
+// AESEncryptWithIV - AES encryption in CBC mode with the explicit iv
+func AESEncryptWithIV(origData, key, iv []byte) ([]byte, error) {
+	block, err := newAESBlock(key)
 	if err != nil {
 		return nil, err
 	}
 	blockSize := block.BlockSize()
+	if len(iv) != blockSize {
+		return nil, ErrIVSize
+	}
 	origData = PKCS5Padding(origData, blockSize)
-	blockMode := cipher.NewCBCEncrypter(block, key[:blockSize])
+	blockMode := cipher.NewCBCEncrypter(block, iv)
 	cryptedData := make([]byte, len(origData))
 	blockMode.CryptBlocks(cryptedData, origData)
 	return cryptedData, nil
 }
 
-// AESDecrypt - AES decryption
-func AESDecrypt(cryptedData, key []byte) ([]byte, error) {
-	block, err := aes.NewCipher(key)
+// AESDecryptWithIV - AES decryption in CBC mode with the explicit iv
+func AESDecryptWithIV(cryptedData, key, iv []byte) ([]byte, error) {
+	block, err := newAESBlock(key)
 	if err != nil {
 		return nil, err
 	}
 	blockSize := block.BlockSize()
-	blockMode := cipher.NewCBCDecrypter(block, key[:blockSize])
+	if len(iv) != blockSize {
+		return nil, ErrIVSize
+	}
+	if len(cryptedData) == 0 || len(cryptedData)%blockSize != 0 {
+		return nil, ErrCiphertextSize
+	}
+	blockMode := cipher.NewCBCDecrypter(block, iv)
 	origData := make([]byte, len(cryptedData))
-
 	blockMode.CryptBlocks(origData, cryptedData)
-	origData = PKCS5UnPadding(origData)
-	return origData, nil
+	return PKCS5UnPadding(origData, blockSize)
 }
 
-// PKCS5Padding - padding algorithm
+// AESEncryptRandomIV - AES encryption in CBC mode with a random iv prepended to the output
+func AESEncryptRandomIV(origData, key []byte) ([]byte, error) {
+	iv := make([]byte, aes.BlockSize)
+	if _, err := rand.Read(iv); err != nil {
+		return nil, fmt.Errorf("crypto: generate iv error, %s", err.Error())
+	}
+	cryptedData, err := AESEncryptWithIV(origData, key, iv)
+	if err != nil {
+		return nil, err
+	}
+	return append(iv, cryptedData...), nil
+}
+
+// AESDecryptRandomIV - AES decryption in CBC mode with the iv read from the input prefix
+func AESDecryptRandomIV(cryptedData, key []byte) ([]byte, error) {
+	if len(cryptedData) < aes.BlockSize {
+		return nil, ErrCiphertextSize
+	}
+	return AESDecryptWithIV(cryptedData[aes.BlockSize:], key, cryptedData[:aes.BlockSize])
+}
+
+func validAESKeySize(key []byte) bool {
+	switch len(key) {
+	case 16, 24, 32:
+		return true
+	default:
+		return false
+	}
+}
+
+func newAESBlock(key []byte) (cipher.Block, error) {
+	if !validAESKeySize(key) {
+		return nil, ErrKeySize
+	}
+	return aes.NewCipher(key)
+}
+
+// PKCS5Padding - padding algorithm, the input is not modified
 func PKCS5Padding(cipherData []byte, blockSize int) []byte {
 	padding := blockSize - len(cipherData)%blockSize
-	paddingData := bytes.Repeat([]byte{byte(padding)}, padding)
-	return append(cipherData, paddingData...)
+	paddedData := make([]byte, len(cipherData), len(cipherData)+padding)
+	copy(paddedData, cipherData)
+	return append(paddedData, bytes.Repeat([]byte{byte(padding)}, padding)...)
 }
 
-// PKCS5UnPadding - unpadding algorithm
-func PKCS5UnPadding(origData []byte) []byte {
+// PKCS5UnPadding - unpadding algorithm, the length and every padding byte are validated
+func PKCS5UnPadding(origData []byte, blockSize int) ([]byte, error) {
 	length := len(origData)
+	if blockSize <= 0 || blockSize > 255 || length == 0 || length%blockSize != 0 {
+		return nil, ErrCiphertextSize
+	}
 	unPadding := int(origData[length-1])
-	return origData[:(length - unPadding)]
+	if unPadding == 0 || unPadding > blockSize {
+		return nil, ErrInvalidPadding
+	}
+	var diff byte
+	for _, b := range origData[length-unPadding:] {
+		diff |= b ^ byte(unPadding)
+	}
+	if diff != 0 {
+		return nil, ErrInvalidPadding
+	}
+	return origData[:(length - unPadding)], nil
 }
@@ -1,7 +1,11 @@
 package crypto
 
 import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
 	"encoding/base64"
+	"errors"
 	"testing"
 )
 
@@ -43,3 +47,95 @@ func TestDecryptUsingAES256(t *testing.T) {
 		}
 	}
 }
+
+func TestAESRandomIV(t *testing.T) {
+	for _, sample := range samples256 {
+		cryptedData, err := AESEncryptRandomIV([]byte(sample[0]), []byte(secret256))
+		if err != nil {
+			t.Fatal(err)
+		}
+		origData, err := AESDecryptRandomIV(cryptedData, []byte(secret256))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(origData) != sample[0] {
+			t.Fatal("invalid aes256 algorithm with random iv")
+		}
+	}
+}
+
+func TestAESDecryptErrors(t *testing.T) {
+	key := []byte(secret256)
+	if _, err := AESEncrypt([]byte("hello"), []byte("short")); !errors.Is(err, ErrKeySize) {
+		t.Fatalf("expect ErrKeySize, got %v", err)
+	}
+	if _, err := AESDecrypt([]byte("hello"), key); !errors.Is(err, ErrCiphertextSize) {
+		t.Fatalf("expect ErrCiphertextSize, got %v", err)
+	}
+	if _, err := AESDecrypt(nil, key); !errors.Is(err, ErrCiphertextSize) {
+		t.Fatalf("expect ErrCiphertextSize, got %v", err)
+	}
+	if _, err := AESDecryptWithIV(make([]byte, 16), key, []byte("iv")); !errors.Is(err, ErrIVSize) {
+		t.Fatalf("expect ErrIVSize, got %v", err)
+	}
+	// valid length but broken padding
+	block, _ := aes.NewCipher(key)
+	cryptedData := make([]byte, 16)
+	cipher.NewCBCEncrypter(block, key).CryptBlocks(cryptedData, bytes.Repeat([]byte{' '}, 16))
+	if _, err := AESDecrypt(cryptedData, key); !errors.Is(err, ErrInvalidPadding) {
+		t.Fatalf("expect ErrInvalidPadding, got %v", err)
+	}
+}
+
+func TestPKCS5UnPadding(t *testing.T) {
+	samples := []struct {
+		data []byte
+		err  error
+	}{
+		{nil, ErrCiphertextSize},
+		{[]byte{1, 2, 3}, ErrCiphertextSize},
+		{[]byte{1, 2, 3, 0}, ErrInvalidPadding},
+		{[]byte{1, 2, 3, 5}, ErrInvalidPadding},
+		{[]byte{1, 3, 2, 2}, nil},
+		{[]byte{1, 2, 3, 3}, ErrInvalidPadding},
+		{[]byte{4, 4, 4, 4}, nil},
+	}
+	for _, sample := range samples {
+		if _, err := PKCS5UnPadding(sample.data, 4); !errors.Is(err, sample.err) {
+			t.Fatalf("unpadding %v, expect %v, got %v", sample.data, sample.err, err)
+		}
+	}
+	origData := []byte("hello")
+	paddedData := PKCS5Padding(origData[:2], 4)
+	if string(origData) != "hello" {
+		t.Fatal("padding should not modify the input")
+	}
+	if unpaddedData, err := PKCS5UnPadding(paddedData, 4); err != nil || string(unpaddedData) != "he" {
+		t.Fatalf("invalid padding round trip, %v", err)
+	}
+}
+
+func FuzzPKCS5UnPadding(f *testing.F) {
+	f.Add([]byte{}, 16)
+	f.Add([]byte{4, 4, 4, 4}, 4)
+	f.Add([]byte{1, 2, 3, 255}, 4)
+	f.Fuzz(func(t *testing.T, data []byte, blockSize int) {
+		unpaddedData, err := PKCS5UnPadding(data, blockSize)
+		if err == nil && len(unpaddedData) >= len(data) {
+			t.Fatal("unpadding should strip at least one byte")
+		}
+	})
+}
+
+func FuzzAESDecrypt(f *testing.F) {
+	for _, sample := range samples256 {
+		cryptedData, _ := base64.StdEncoding.DecodeString(sample[1])
+		f.Add(cryptedData)
+	}
+	f.Add([]byte{})
+	f.Add([]byte("not a block"))
+	f.Fuzz(func(t *testing.T, cryptedData []byte) {
+		AESDecrypt(cryptedData, []byte(secret256))
+		AESDecryptRandomIV(cryptedData, []byte(secret256))
+	})
+}
@@ -0,0 +1,47 @@
+package crypto
+
+import "errors"
+
+var (
+	// ErrKeySize is returned when the key length does not match the cipher
+	ErrKeySize = errors.New("crypto: invalid key size")
+
+	// ErrIVSize is returned when the iv length does not match the block size
+	ErrIVSize = errors.New("crypto: invalid iv size")
+
+	// ErrCiphertextSize is returned when the ciphertext is empty or not a multiple of the block size
+	ErrCiphertextSize = errors.New("crypto: invalid ciphertext size")
+
+	// ErrInvalidPadding is returned when the padding bytes are broken
+	ErrInvalidPadding = errors.New("crypto: invalid padding")
+
+	// ErrInvalidEnvelope is returned when the envelope can not be parsed
+	ErrInvalidEnvelope = errors.New("crypto: invalid envelope")
+
+	// ErrUnsupportedVersion is returned when the envelope version is unknown
+	ErrUnsupportedVersion = errors.New("crypto: unsupported envelope version")
+
+	// ErrUnsupportedAlgorithm is returned when the envelope algorithm is unknown
+	ErrUnsupportedAlgorithm = errors.New("crypto: unsupported algorithm")
+
+	// ErrInvalidStreamHeader is returned when the encrypted stream header is broken
+	ErrInvalidStreamHeader = errors.New("crypto: invalid stream header")
+
+	// ErrStreamClosed is returned when writing to a closed encrypt writer
+	ErrStreamClosed = errors.New("crypto: write to closed stream")
+
+	// ErrKeyIDTooLong is returned when the key id does not fit in the envelope header
+	ErrKeyIDTooLong = errors.New("crypto: key id too long")
+
+	// ErrKeyIDMismatch is returned when the envelope is sealed by another key
+	ErrKeyIDMismatch = errors.New("crypto: key id mismatch")
+
+	// ErrKeyNotFound is returned when the key id is not in the keyring
+	ErrKeyNotFound = errors.New("crypto: key not found")
+
+	// ErrNoPrimaryKey is returned when the keyring has no primary key to encrypt with
+	ErrNoPrimaryKey = errors.New("crypto: no primary key")
+
+	// ErrAuthFailed is returned when the ciphertext or associated data is tampered
+	ErrAuthFailed = errors.New("crypto: message authentication failed")
+)
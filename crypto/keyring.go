@@ -0,0 +1,239 @@
+package crypto
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+)
+
+// Key is a named key used by the keyring
+type Key struct {
+	ID        string    `json:"id"`
+	Algorithm Algorithm `json:"algorithm"`
+	Material  []byte    `json:"key"` // base64 encoded in json
+}
+
+// KeySet is a group of keys loaded from a key source
+type KeySet struct {
+	Primary string `json:"primary"`
+	Keys    []Key  `json:"keys"`
+}
+
+// KeySource loads keys for the keyring
+type KeySource interface {
+	LoadKeys() (*KeySet, error)
+}
+
+// KeySourceFunc is an adapter to use ordinary functions as key sources
+type KeySourceFunc func() (*KeySet, error)
+
+// LoadKeys calls f()
+func (f KeySourceFunc) LoadKeys() (*KeySet, error) {
+	return f()
+}
+
+// Keyring holds multiple keys with one marked primary, it is routine-safe.
+//
+// New data is always encrypted with the primary key, and the key id in the
+// envelope decides which key is used to decrypt, so old payloads are still
+// readable after the primary key is rotated.
+type Keyring struct {
+	mu      sync.RWMutex
+	boxes   map[string]*SealedBox
+	primary string
+}
+
+// NewKeyring creates an empty keyring
+func NewKeyring() *Keyring {
+	return &Keyring{
+		boxes: make(map[string]*SealedBox),
+	}
+}
+
+// NewKeyringFromSources creates a keyring and loads keys from the sources in order
+func NewKeyringFromSources(sources ...KeySource) (*Keyring, error) {
+	r := NewKeyring()
+	if err := r.Load(sources...); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// Load loads keys from the sources in order, the primary key of a later source wins.
+// The keyring is changed only when all the sources are loaded.
+func (r *Keyring) Load(sources ...KeySource) error {
+	keySets := make([]*KeySet, 0, len(sources))
+	boxes := make(map[string]*SealedBox)
+	for _, source := range sources {
+		keySet, err := source.LoadKeys()
+		if err != nil {
+			return fmt.Errorf("crypto: load keys error, %w", err)
+		}
+		for _, key := range keySet.Keys {
+			box, err := NewSealedBox(key.Algorithm, key.ID, key.Material)
+			if err != nil {
+				return fmt.Errorf("crypto: add key %q error, %w", key.ID, err)
+			}
+			boxes[key.ID] = box
+		}
+		keySets = append(keySets, keySet)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	primary := r.primary
+	for _, keySet := range keySets {
+		for _, key := range keySet.Keys {
+			if primary == "" {
+				primary = key.ID
+			}
+		}
+		if keySet.Primary == "" {
+			continue
+		}
+		if _, ok := boxes[keySet.Primary]; !ok {
+			if _, ok := r.boxes[keySet.Primary]; !ok {
+				return fmt.Errorf("crypto: set primary key %q error, %w", keySet.Primary, ErrKeyNotFound)
+			}
+		}
+		primary = keySet.Primary
+	}
+	for keyID, box := range boxes {
+		r.boxes[keyID] = box
+	}
+	r.primary = primary
+	return nil
+}
+
+// AddKey adds or replaces the key, the first key added becomes the primary
+func (r *Keyring) AddKey(key Key) error {
+	box, err := NewSealedBox(key.Algorithm, key.ID, key.Material)
+	if err != nil {
+		return err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.boxes[key.ID] = box
+	if r.primary == "" {
+		r.primary = key.ID
+	}
+	return nil
+}
+
+// RemoveKey removes the key, the primary key can not be removed
+func (r *Keyring) RemoveKey(keyID string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.boxes[keyID]; !ok {
+		return ErrKeyNotFound
+	}
+	if keyID == r.primary {
+		return fmt.Errorf("crypto: can not remove primary key %q", keyID)
+	}
+	delete(r.boxes, keyID)
+	return nil
+}
+
+// SetPrimary marks the key as primary
+func (r *Keyring) SetPrimary(keyID string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.boxes[keyID]; !ok {
+		return ErrKeyNotFound
+	}
+	r.primary = keyID
+	return nil
+}
+
+// Primary returns the primary key id
+func (r *Keyring) Primary() string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.primary
+}
+
+// KeyIDs returns the sorted key ids in the keyring
+func (r *Keyring) KeyIDs() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	keyIDs := make([]string, 0, len(r.boxes))
+	for keyID := range r.boxes {
+		keyIDs = append(keyIDs, keyID)
+	}
+	sort.Strings(keyIDs)
+	return keyIDs
+}
+
+func (r *Keyring) primaryBox() (*SealedBox, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	box, ok := r.boxes[r.primary]
+	if !ok {
+		return nil, ErrNoPrimaryKey
+	}
+	return box, nil
+}
+
+func (r *Keyring) box(keyID string) (*SealedBox, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	box, ok := r.boxes[keyID]
+	if !ok {
+		return nil, ErrKeyNotFound
+	}
+	return box, nil
+}
+
+// Encrypt seals the plaintext with the primary key
+func (r *Keyring) Encrypt(plaintext, additionalData []byte) ([]byte, error) {
+	box, err := r.primaryBox()
+	if err != nil {
+		return nil, err
+	}
+	return box.Seal(plaintext, additionalData)
+}
+
+// Decrypt opens the envelope with the key referenced by its key id
+func (r *Keyring) Decrypt(data, additionalData []byte) ([]byte, error) {
+	env, err := ParseEnvelope(data)
+	if err != nil {
+		return nil, err
+	}
+	box, err := r.box(env.KeyID)
+	if err != nil {
+		return nil, err
+	}
+	return box.OpenEnvelope(env, additionalData)
+}
+
+// NeedsReencrypt checks whether the envelope is sealed by a key other than the primary
+func (r *Keyring) NeedsReencrypt(data []byte) (bool, error) {
+	env, err := ParseEnvelope(data)
+	if err != nil {
+		return false, err
+	}
+	return env.KeyID != r.Primary(), nil
+}
+
+// Reencrypt moves the stored envelope onto the primary key,
+// the data is returned as is when it is already sealed by the primary key.
+func (r *Keyring) Reencrypt(data, additionalData []byte) (output []byte, rotated bool, err error) {
+	needsReencrypt, err := r.NeedsReencrypt(data)
+	if err != nil {
+		return
+	}
+	if !needsReencrypt {
+		output = data
+		return
+	}
+	plaintext, err := r.Decrypt(data, additionalData)
+	if err != nil {
+		return
+	}
+	output, err = r.Encrypt(plaintext, additionalData)
+	if err != nil {
+		return
+	}
+	rotated = true
+	return
+}
@@ -0,0 +1,246 @@
+package crypto
+
+import (
+	"bufio"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// decodeKeyMaterial decodes the base64 encoded key
+func decodeKeyMaterial(encoded string) ([]byte, error) {
+	material, err := base64.StdEncoding.DecodeString(strings.TrimSpace(encoded))
+	if err != nil {
+		return nil, fmt.Errorf("decode key material error, %w", err)
+	}
+	return material, nil
+}
+
+func defaultAlgorithm(alg Algorithm) Algorithm {
+	if alg == 0 {
+		return AES256GCM
+	}
+	return alg
+}
+
+// EnvKeySource loads keys from the environment variables.
+//
+// The variables are named as below, where the key is base64 encoded:
+//
+//	{Prefix}KEY_{ID}=base64
+//	{Prefix}PRIMARY={ID}
+//	{Prefix}ALGORITHM=AES-256-GCM
+//
+// The keys are sorted by id, so the first one is the primary when {Prefix}PRIMARY is not set.
+type EnvKeySource struct {
+	Prefix    string
+	Algorithm Algorithm // used when {Prefix}ALGORITHM is not set, default AES-256-GCM
+}
+
+// NewEnvKeySource creates a key source from the environment variables
+func NewEnvKeySource(prefix string) *EnvKeySource {
+	return &EnvKeySource{Prefix: prefix}
+}
+
+// LoadKeys implements KeySource
+func (s *EnvKeySource) LoadKeys() (keySet *KeySet, err error) {
+	alg := defaultAlgorithm(s.Algorithm)
+	if v, ok := os.LookupEnv(s.Prefix + "ALGORITHM"); ok {
+		if alg, err = ParseAlgorithm(v); err != nil {
+			err = fmt.Errorf("env %sALGORITHM, %w", s.Prefix, err)
+			return
+		}
+	}
+	keySet = &KeySet{Primary: os.Getenv(s.Prefix + "PRIMARY")}
+	keyPrefix := s.Prefix + "KEY_"
+	for _, env := range os.Environ() {
+		name, value, _ := strings.Cut(env, "=")
+		if !strings.HasPrefix(name, keyPrefix) || name == keyPrefix {
+			continue
+		}
+		material, decodeErr := decodeKeyMaterial(value)
+		if decodeErr != nil {
+			err = fmt.Errorf("env %s, %w", name, decodeErr)
+			return
+		}
+		keySet.Keys = append(keySet.Keys, Key{
+			ID:        strings.TrimPrefix(name, keyPrefix),
+			Algorithm: alg,
+			Material:  material,
+		})
+	}
+	slices.SortFunc(keySet.Keys, func(a, b Key) int { return strings.Compare(a.ID, b.ID) })
+	return
+}
+
+// DirKeySource loads keys from the files in a directory, such as mounted secrets.
+//
+// Each {ID}.key file holds one base64 encoded key, and the optional file
+// named primary holds the primary key id.
+type DirKeySource struct {
+	Dir       string
+	Algorithm Algorithm // default AES-256-GCM
+}
+
+// NewDirKeySource creates a key source from the directory
+func NewDirKeySource(dir string) *DirKeySource {
+	return &DirKeySource{Dir: dir}
+}
+
+// LoadKeys implements KeySource
+func (s *DirKeySource) LoadKeys() (keySet *KeySet, err error) {
+	keyFiles, err := filepath.Glob(filepath.Join(s.Dir, "*.key"))
+	if err != nil {
+		return
+	}
+	keySet = &KeySet{}
+	for _, keyFile := range keyFiles {
+		encoded, readErr := os.ReadFile(keyFile)
+		if readErr != nil {
+			err = readErr
+			return
+		}
+		material, decodeErr := decodeKeyMaterial(string(encoded))
+		if decodeErr != nil {
+			err = fmt.Errorf("file %s, %w", keyFile, decodeErr)
+			return
+		}
+		keySet.Keys = append(keySet.Keys, Key{
+			ID:        strings.TrimSuffix(filepath.Base(keyFile), ".key"),
+			Algorithm: defaultAlgorithm(s.Algorithm),
+			Material:  material,
+		})
+	}
+	primary, readErr := os.ReadFile(filepath.Join(s.Dir, "primary"))
+	if readErr != nil && !os.IsNotExist(readErr) {
+		err = readErr
+		return
+	}
+	keySet.Primary = strings.TrimSpace(string(primary))
+	return
+}
+
+// JSONKeyFileSource loads keys from a json file in the format of KeySet
+//
+//	{"primary": "v2", "keys": [{"id": "v1", "algorithm": "AES-256-GCM", "key": "base64"}]}
+type JSONKeyFileSource struct {
+	Path string
+}
+
+// NewJSONKeyFileSource creates a key source from the json key file
+func NewJSONKeyFileSource(path string) *JSONKeyFileSource {
+	return &JSONKeyFileSource{Path: path}
+}
+
+// LoadKeys implements KeySource
+func (s *JSONKeyFileSource) LoadKeys() (keySet *KeySet, err error) {
+	data, err := os.ReadFile(s.Path)
+	if err != nil {
+		return
+	}
+	keySet = &KeySet{}
+	if err = json.Unmarshal(data, keySet); err != nil {
+		err = fmt.Errorf("parse key file %s error, %w", s.Path, err)
+		return
+	}
+	for i := range keySet.Keys {
+		keySet.Keys[i].Algorithm = defaultAlgorithm(keySet.Keys[i].Algorithm)
+	}
+	return
+}
+
+// INIKeyFileSource loads keys from an ini file, each section is a key
+//
+//	primary = v2
+//
+//	[v1]
+//	algorithm = AES-256-GCM
+//	key = base64
+type INIKeyFileSource struct {
+	Path string
+}
+
+// NewINIKeyFileSource creates a key source from the ini key file
+func NewINIKeyFileSource(path string) *INIKeyFileSource {
+	return &INIKeyFileSource{Path: path}
+}
+
+// LoadKeys implements KeySource
+func (s *INIKeyFileSource) LoadKeys() (keySet *KeySet, err error) {
+	fh, err := os.Open(s.Path)
+	if err != nil {
+		return
+	}
+	defer fh.Close()
+
+	keySet = &KeySet{}
+	var current *Key
+	flush := func() error {
+		if current == nil {
+			return nil
+		}
+		if len(current.Material) == 0 {
+			return fmt.Errorf("key %q has no key material", current.ID)
+		}
+		current.Algorithm = defaultAlgorithm(current.Algorithm)
+		keySet.Keys = append(keySet.Keys, *current)
+		return nil
+	}
+
+	scanner := bufio.NewScanner(fh)
+	lineNo := 0
+	for scanner.Scan() {
+		lineNo++
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") || strings.HasPrefix(line, ";") {
+			continue
+		}
+		if strings.HasPrefix(line, "[") && strings.HasSuffix(line, "]") {
+			if err = flush(); err != nil {
+				return
+			}
+			current = &Key{ID: strings.TrimSpace(line[1 : len(line)-1])}
+			continue
+		}
+		name, value, found := strings.Cut(line, "=")
+		if !found {
+			err = fmt.Errorf("parse key file %s error, invalid line %d", s.Path, lineNo)
+			return
+		}
+		name = strings.ToLower(strings.TrimSpace(name))
+		value = strings.TrimSpace(value)
+		switch {
+		case current == nil && name == "primary":
+			keySet.Primary = value
+		case current != nil && name == "algorithm":
+			if current.Algorithm, err = ParseAlgorithm(value); err != nil {
+				err = fmt.Errorf("parse key file %s error, line %d, %w", s.Path, lineNo, err)
+				return
+			}
+		case current != nil && name == "key":
+			if current.Material, err = decodeKeyMaterial(value); err != nil {
+				err = fmt.Errorf("parse key file %s error, line %d, %w", s.Path, lineNo, err)
+				return
+			}
+		}
+	}
+	if err = scanner.Err(); err != nil {
+		return
+	}
+	err = flush()
+	return
+}
+
+// NewKeyFileSource creates a json or ini key source by the file extension
+func NewKeyFileSource(path string) KeySource {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".ini", ".conf", ".cfg":
+		return NewINIKeyFileSource(path)
+	default:
+		return NewJSONKeyFileSource(path)
+	}
+}
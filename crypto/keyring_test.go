@@ -0,0 +1,181 @@
+package crypto
+
+import (
+	"encoding/base64"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+var (
+	keyringKeyV1 = []byte("aaaabbbbccccddddaaaabbbbccccdddd")
+	keyringKeyV2 = []byte("eeeeffffgggghhhheeeeffffgggghhhh")
+)
+
+func TestKeyringRotation(t *testing.T) {
+	keyring := NewKeyring()
+	if _, err := keyring.Encrypt([]byte("hello"), nil); !errors.Is(err, ErrNoPrimaryKey) {
+		t.Fatalf("expect ErrNoPrimaryKey, got %v", err)
+	}
+	if err := keyring.AddKey(Key{ID: "v1", Algorithm: AES256GCM, Material: keyringKeyV1}); err != nil {
+		t.Fatal(err)
+	}
+	oldData, err := keyring.Encrypt([]byte("hello"), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// rotate to v2
+	if err := keyring.AddKey(Key{ID: "v2", Algorithm: ChaCha20Poly1305, Material: keyringKeyV2}); err != nil {
+		t.Fatal(err)
+	}
+	if err := keyring.SetPrimary("v2"); err != nil {
+		t.Fatal(err)
+	}
+	newData, _ := keyring.Encrypt([]byte("world"), nil)
+	if env, _ := ParseEnvelope(newData); env.KeyID != "v2" {
+		t.Fatalf("expect primary key v2, got %s", env.KeyID)
+	}
+	if origData, err := keyring.Decrypt(oldData, nil); err != nil || string(origData) != "hello" {
+		t.Fatalf("old data should be readable after rotation, %v", err)
+	}
+
+	// re-encrypt old data onto v2
+	rotatedData, rotated, err := keyring.Reencrypt(oldData, nil)
+	if err != nil || !rotated {
+		t.Fatalf("old data should be rotated, %v", err)
+	}
+	if _, rotated, _ = keyring.Reencrypt(rotatedData, nil); rotated {
+		t.Fatal("data sealed by primary key should not be rotated")
+	}
+	if err := keyring.RemoveKey("v1"); err != nil {
+		t.Fatal(err)
+	}
+	if origData, err := keyring.Decrypt(rotatedData, nil); err != nil || string(origData) != "hello" {
+		t.Fatalf("rotated data should be readable, %v", err)
+	}
+	if _, err := keyring.Decrypt(oldData, nil); !errors.Is(err, ErrKeyNotFound) {
+		t.Fatalf("expect ErrKeyNotFound, got %v", err)
+	}
+	if err := keyring.RemoveKey("v2"); err == nil {
+		t.Fatal("primary key should not be removed")
+	}
+}
+
+func TestKeyringSources(t *testing.T) {
+	dir := t.TempDir()
+	encodedV1 := base64.StdEncoding.EncodeToString(keyringKeyV1)
+	encodedV2 := base64.StdEncoding.EncodeToString(keyringKeyV2)
+
+	jsonFile := filepath.Join(dir, "keys.json")
+	os.WriteFile(jsonFile, []byte(`{"primary":"v1","keys":[{"id":"v1","key":"`+encodedV1+`"}]}`), 0600)
+
+	iniFile := filepath.Join(dir, "keys.ini")
+	os.WriteFile(iniFile, []byte("; rotated at 2024-06\nprimary = v2\n\n[v2]\nalgorithm = ChaCha20-Poly1305\nkey = "+encodedV2+"\n"), 0600)
+
+	keyDir := filepath.Join(dir, "secrets")
+	os.Mkdir(keyDir, 0700)
+	os.WriteFile(filepath.Join(keyDir, "v3.key"), []byte(encodedV1+"\n"), 0600)
+
+	t.Setenv("TEST_KEYRING_KEY_v4", encodedV2)
+	t.Setenv("TEST_KEYRING_PRIMARY", "v4")
+
+	keyring, err := NewKeyringFromSources(
+		NewKeyFileSource(jsonFile),
+		NewKeyFileSource(iniFile),
+		NewDirKeySource(keyDir),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if keyring.Primary() != "v2" {
+		t.Fatalf("expect primary key v2, got %s", keyring.Primary())
+	}
+	if err := keyring.Load(NewEnvKeySource("TEST_KEYRING_")); err != nil {
+		t.Fatal(err)
+	}
+	if keyring.Primary() != "v4" {
+		t.Fatalf("expect primary key v4, got %s", keyring.Primary())
+	}
+	keyIDs := keyring.KeyIDs()
+	if len(keyIDs) != 4 {
+		t.Fatalf("expect 4 keys, got %v", keyIDs)
+	}
+	for _, keyID := range keyIDs {
+		if err := keyring.SetPrimary(keyID); err != nil {
+			t.Fatal(err)
+		}
+		data, _ := keyring.Encrypt([]byte("hello"), nil)
+		if origData, err := keyring.Decrypt(data, nil); err != nil || string(origData) != "hello" {
+			t.Fatalf("key %s should work, %v", keyID, err)
+		}
+	}
+}
+
+func TestKeyringEnvSourceOrder(t *testing.T) {
+	encoded := base64.StdEncoding.EncodeToString(keyringKeyV1)
+	t.Setenv("TEST_ORDER_KEY_c", encoded)
+	t.Setenv("TEST_ORDER_KEY_a", encoded)
+	t.Setenv("TEST_ORDER_KEY_b", encoded)
+	keyring, err := NewKeyringFromSources(NewEnvKeySource("TEST_ORDER_"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if keyring.Primary() != "a" {
+		t.Fatalf("expect the first key by id as primary, got %s", keyring.Primary())
+	}
+
+	_, err = NewKeyringFromSources(NewJSONKeyFileSource(filepath.Join(t.TempDir(), "missing.json")))
+	if !errors.Is(err, os.ErrNotExist) {
+		t.Fatalf("load error should be wrapped, got %v", err)
+	}
+}
+
+func TestKeyringSourceErrors(t *testing.T) {
+	iniFile := filepath.Join(t.TempDir(), "keys.ini")
+	os.WriteFile(iniFile, []byte("[v1]\nalgorithm = ROT13\n"), 0600)
+	if _, err := NewKeyringFromSources(NewINIKeyFileSource(iniFile)); !errors.Is(err, ErrUnsupportedAlgorithm) {
+		t.Fatalf("algorithm error should be wrapped, got %v", err)
+	}
+
+	t.Setenv("TEST_BROKEN_KEY_v1", "not base64!")
+	_, err := NewKeyringFromSources(NewEnvKeySource("TEST_BROKEN_"))
+	var corruptErr base64.CorruptInputError
+	if !errors.As(err, &corruptErr) {
+		t.Fatalf("decode error should be wrapped, got %v", err)
+	}
+
+	jsonFile := filepath.Join(t.TempDir(), "keys.json")
+	os.WriteFile(jsonFile, []byte(`{"keys":[{"id":"v1","key":"`+base64.StdEncoding.EncodeToString([]byte("short"))+`"}]}`), 0600)
+	if _, err := NewKeyringFromSources(NewJSONKeyFileSource(jsonFile)); !errors.Is(err, ErrKeySize) {
+		t.Fatalf("key size error should be wrapped, got %v", err)
+	}
+}
+
+func TestKeyringLoadAtomic(t *testing.T) {
+	keyring := NewKeyring()
+	keyring.AddKey(Key{ID: "v1", Algorithm: AES256GCM, Material: keyringKeyV1})
+	valid := KeySourceFunc(func() (*KeySet, error) {
+		return &KeySet{Primary: "v2", Keys: []Key{{ID: "v2", Algorithm: AES256GCM, Material: keyringKeyV2}}}, nil
+	})
+	errBroken := errors.New("broken")
+	broken := KeySourceFunc(func() (*KeySet, error) { return nil, errBroken })
+	if err := keyring.Load(valid, broken); !errors.Is(err, errBroken) {
+		t.Fatalf("load error should be returned, got %v", err)
+	}
+	missingPrimary := KeySourceFunc(func() (*KeySet, error) { return &KeySet{Primary: "v3"}, nil })
+	if err := keyring.Load(valid, missingPrimary); !errors.Is(err, ErrKeyNotFound) {
+		t.Fatalf("unknown primary should be rejected, got %v", err)
+	}
+	if keyIDs := keyring.KeyIDs(); len(keyIDs) != 1 || keyring.Primary() != "v1" {
+		t.Fatalf("failed load should not change the keyring, got %v, primary %s", keyIDs, keyring.Primary())
+	}
+
+	if err := keyring.Load(valid); err != nil {
+		t.Fatal(err)
+	}
+	if keyIDs := keyring.KeyIDs(); len(keyIDs) != 2 || keyring.Primary() != "v2" {
+		t.Fatalf("unexpected keyring %v, primary %s", keyIDs, keyring.Primary())
+	}
+}
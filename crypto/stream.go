@@ -0,0 +1,236 @@
+package crypto
+
+import (
+	"bufio"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// StreamVersion is the current version of the encrypted stream format
+const StreamVersion byte = 1
+
+const (
+	// DefaultSegmentSize is the default plaintext size of each stream segment
+	DefaultSegmentSize = 64 * 1024
+	// MaxSegmentSize is the max plaintext size of each stream segment
+	MaxSegmentSize = 16 * 1024 * 1024
+
+	streamNoncePrefixSize = 7
+	streamHeaderSize      = 1 + 1 + 4 + streamNoncePrefixSize
+)
+
+// The encrypted stream follows the STREAM construction, the plaintext is split
+// into segments which are sealed independently with AES-256-GCM:
+//
+//	header:  version(1) | algorithm(1) | segmentSize(4) | noncePrefix(7)
+//	segment: ciphertext | tag
+//
+// The nonce of each segment is noncePrefix(7) | counter(4) | lastFlag(1), and the
+// header is authenticated as the associated data of every segment. So reordered
+// segments fail with the counter, a truncated stream fails with the last flag,
+// and bit flips fail with the tag.
+
+type streamCipher struct {
+	aead    cipher.AEAD
+	header  []byte
+	nonce   []byte
+	counter uint32
+}
+
+func (s *streamCipher) nextNonce(last bool) ([]byte, error) {
+	if s.counter == ^uint32(0) {
+		return nil, errors.New("crypto: too many stream segments")
+	}
+	binary.BigEndian.PutUint32(s.nonce[streamNoncePrefixSize:], s.counter)
+	s.nonce[len(s.nonce)-1] = 0
+	if last {
+		s.nonce[len(s.nonce)-1] = 1
+	}
+	s.counter++
+	return s.nonce, nil
+}
+
+// EncryptWriter encrypts the data written to it in segments, it is not routine-safe.
+// Close must be called to write the final segment.
+type EncryptWriter struct {
+	w      io.Writer
+	cipher *streamCipher
+	buf    []byte
+	out    []byte
+	err    error
+	closed bool
+}
+
+// NewEncryptWriter creates an encrypt writer with the 32-byte key and default segment size
+func NewEncryptWriter(w io.Writer, key []byte) (*EncryptWriter, error) {
+	return NewEncryptWriterSize(w, key, DefaultSegmentSize)
+}
+
+// NewEncryptWriterSize creates an encrypt writer with the 32-byte key and segment size
+func NewEncryptWriterSize(w io.Writer, key []byte, segmentSize int) (*EncryptWriter, error) {
+	if segmentSize <= 0 || segmentSize > MaxSegmentSize {
+		return nil, fmt.Errorf("crypto: invalid segment size %d", segmentSize)
+	}
+	aead, err := newAEAD(AES256GCM, key)
+	if err != nil {
+		return nil, err
+	}
+	header := make([]byte, streamHeaderSize)
+	header[0] = StreamVersion
+	header[1] = byte(AES256GCM)
+	binary.BigEndian.PutUint32(header[2:6], uint32(segmentSize))
+	if _, err := rand.Read(header[6:]); err != nil {
+		return nil, fmt.Errorf("crypto: generate nonce error, %s", err.Error())
+	}
+	if _, err := w.Write(header); err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, aead.NonceSize())
+	copy(nonce, header[6:])
+	return &EncryptWriter{
+		w:      w,
+		cipher: &streamCipher{aead: aead, header: header, nonce: nonce},
+		buf:    make([]byte, 0, segmentSize),
+		out:    make([]byte, 0, segmentSize+aead.Overhead()),
+	}, nil
+}
+
+// Write implements io.Writer
+func (e *EncryptWriter) Write(p []byte) (n int, err error) {
+	if e.closed {
+		return 0, ErrStreamClosed
+	}
+	if e.err != nil {
+		return 0, e.err
+	}
+	for len(p) > 0 {
+		// only flush the full segment when more data comes, since the last one must be flagged
+		if len(e.buf) == cap(e.buf) {
+			if err = e.flush(false); err != nil {
+				return
+			}
+		}
+		copied := copy(e.buf[len(e.buf):cap(e.buf)], p)
+		e.buf = e.buf[:len(e.buf)+copied]
+		p = p[copied:]
+		n += copied
+	}
+	return
+}
+
+// Close writes the final segment, it does not close the underlying writer
+func (e *EncryptWriter) Close() error {
+	if e.closed {
+		return nil
+	}
+	e.closed = true
+	if e.err != nil {
+		return e.err
+	}
+	return e.flush(true)
+}
+
+func (e *EncryptWriter) flush(last bool) error {
+	nonce, err := e.cipher.nextNonce(last)
+	if err != nil {
+		e.err = err
+		return err
+	}
+	e.out = e.cipher.aead.Seal(e.out[:0], nonce, e.buf, e.cipher.header)
+	e.buf = e.buf[:0]
+	if _, err = e.w.Write(e.out); err != nil {
+		e.err = err
+	}
+	return err
+}
+
+// DecryptReader decrypts the stream created by EncryptWriter, it is not routine-safe.
+//
+// Each segment is authenticated before it is returned, so the caller never reads
+// unverified data, and io.EOF is only returned after the final segment is verified.
+type DecryptReader struct {
+	r      *bufio.Reader
+	cipher *streamCipher
+	in     []byte
+	out    []byte
+	pos    int
+	err    error
+	done   bool
+}
+
+// NewDecryptReader creates a decrypt reader with the 32-byte key, the stream header is read at once
+func NewDecryptReader(r io.Reader, key []byte) (*DecryptReader, error) {
+	header := make([]byte, streamHeaderSize)
+	if _, err := io.ReadFull(r, header); err != nil {
+		return nil, ErrInvalidStreamHeader
+	}
+	if header[0] != StreamVersion {
+		return nil, ErrUnsupportedVersion
+	}
+	aead, err := newAEAD(Algorithm(header[1]), key)
+	if err != nil {
+		return nil, err
+	}
+	segmentSize := int(binary.BigEndian.Uint32(header[2:6]))
+	if segmentSize <= 0 || segmentSize > MaxSegmentSize {
+		return nil, ErrInvalidStreamHeader
+	}
+	nonce := make([]byte, aead.NonceSize())
+	copy(nonce, header[6:])
+	return &DecryptReader{
+		r:      bufio.NewReader(r),
+		cipher: &streamCipher{aead: aead, header: header, nonce: nonce},
+		in:     make([]byte, segmentSize+aead.Overhead()),
+		out:    make([]byte, 0, segmentSize),
+	}, nil
+}
+
+// Read implements io.Reader
+func (d *DecryptReader) Read(p []byte) (n int, err error) {
+	for d.pos == len(d.out) {
+		if d.err != nil {
+			return 0, d.err
+		}
+		if d.done {
+			return 0, io.EOF
+		}
+		d.err = d.next()
+	}
+	n = copy(p, d.out[d.pos:])
+	d.pos += n
+	return
+}
+
+func (d *DecryptReader) next() error {
+	n, err := io.ReadFull(d.r, d.in)
+	last := false
+	switch err {
+	case nil:
+		// a full segment is the last one only when nothing follows
+		if _, peekErr := d.r.Peek(1); peekErr == io.EOF {
+			last = true
+		} else if peekErr != nil {
+			return peekErr
+		}
+	case io.EOF, io.ErrUnexpectedEOF:
+		last = true
+	default:
+		return err
+	}
+	nonce, err := d.cipher.nextNonce(last)
+	if err != nil {
+		return err
+	}
+	out, err := d.cipher.aead.Open(d.out[:0], nonce, d.in[:n], d.cipher.header)
+	if err != nil {
+		return ErrAuthFailed
+	}
+	d.out = out
+	d.pos = 0
+	d.done = last
+	return nil
+}
@@ -0,0 +1,97 @@
+package crypto
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"testing"
+)
+
+var streamKey = []byte("aaaabbbbccccddddaaaabbbbccccdddd")
+
+func encryptStream(t *testing.T, plaintext []byte, segmentSize int) []byte {
+	var buf bytes.Buffer
+	w, err := NewEncryptWriterSize(&buf, streamKey, segmentSize)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// write in odd sized pieces to cross the segment boundaries
+	for len(plaintext) > 0 {
+		n := min(7, len(plaintext))
+		if _, err := w.Write(plaintext[:n]); err != nil {
+			t.Fatal(err)
+		}
+		plaintext = plaintext[n:]
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func decryptStream(ciphertext []byte) ([]byte, error) {
+	r, err := NewDecryptReader(bytes.NewReader(ciphertext), streamKey)
+	if err != nil {
+		return nil, err
+	}
+	return io.ReadAll(r)
+}
+
+func TestStreamRoundTrip(t *testing.T) {
+	for _, size := range []int{0, 1, 31, 32, 33, 64, 1000} {
+		plaintext := bytes.Repeat([]byte("0123456789"), size/10+1)[:size]
+		ciphertext := encryptStream(t, plaintext, 32)
+		decrypted, err := decryptStream(ciphertext)
+		if err != nil {
+			t.Fatalf("size %d, %v", size, err)
+		}
+		if !bytes.Equal(decrypted, plaintext) {
+			t.Fatalf("size %d, invalid stream decryption", size)
+		}
+	}
+}
+
+func TestStreamTampered(t *testing.T) {
+	plaintext := bytes.Repeat([]byte("a"), 100)
+	ciphertext := encryptStream(t, plaintext, 32)
+	segment := 32 + 16
+
+	// truncated at the segment boundary
+	truncated := ciphertext[:streamHeaderSize+2*segment]
+	if _, err := decryptStream(truncated); !errors.Is(err, ErrAuthFailed) {
+		t.Fatalf("truncation should be detected, got %v", err)
+	}
+
+	// reordered segments
+	reordered := append([]byte(nil), ciphertext...)
+	copy(reordered[streamHeaderSize:], ciphertext[streamHeaderSize+segment:streamHeaderSize+2*segment])
+	copy(reordered[streamHeaderSize+segment:], ciphertext[streamHeaderSize:streamHeaderSize+segment])
+	if _, err := decryptStream(reordered); !errors.Is(err, ErrAuthFailed) {
+		t.Fatalf("reordering should be detected, got %v", err)
+	}
+
+	// appended data
+	if _, err := decryptStream(append(append([]byte(nil), ciphertext...), 0)); !errors.Is(err, ErrAuthFailed) {
+		t.Fatalf("appended data should be detected, got %v", err)
+	}
+
+	// bit flips
+	for i := 1; i < len(ciphertext); i++ {
+		flipped := append([]byte(nil), ciphertext...)
+		flipped[i] ^= 0x01
+		if _, err := decryptStream(flipped); err == nil {
+			t.Fatalf("flipped byte %d should be detected", i)
+		}
+	}
+}
+
+func TestStreamClosed(t *testing.T) {
+	w, _ := NewEncryptWriter(io.Discard, streamKey)
+	w.Close()
+	if _, err := w.Write([]byte("hello")); !errors.Is(err, ErrStreamClosed) {
+		t.Fatalf("expect ErrStreamClosed, got %v", err)
+	}
+	if _, err := NewDecryptReader(bytes.NewReader([]byte{StreamVersion}), streamKey); !errors.Is(err, ErrInvalidStreamHeader) {
+		t.Fatalf("expect ErrInvalidStreamHeader, got %v", err)
+	}
+}
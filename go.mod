@@ -6,14 +6,16 @@ toolchain go1.23.0
 
 require (
 	github.com/andreburgaud/crypt2go v1.8.0
+	github.com/cespare/xxhash/v2 v2.3.0
 	github.com/lestrrat-go/file-rotatelogs v2.4.0+incompatible
 	go.uber.org/zap v1.27.0
+	golang.org/x/crypto v0.33.0
 )
 
 require (
 	github.com/jonboulle/clockwork v0.3.0 // indirect
 	github.com/lestrrat-go/strftime v1.0.6 // indirect
 	github.com/pkg/errors v0.9.1 // indirect
-	go.uber.org/atomic v1.7.0 // indirect
 	go.uber.org/multierr v1.10.0 // indirect
+	golang.org/x/sys v0.30.0 // indirect
 )
@@ -0,0 +1,191 @@
+package hash
+
+import (
+	"crypto/md5"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/base32"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	gohash "hash"
+	"hash/crc32"
+	"hash/fnv"
+	"io"
+	"os"
+	"sort"
+	"sync"
+
+	"github.com/cespare/xxhash/v2"
+	"golang.org/x/crypto/blake2b"
+	"golang.org/x/crypto/sha3"
+)
+
+// ErrUnknownAlgorithm is returned when the hash algorithm is not registered
+var ErrUnknownAlgorithm = errors.New("hash: unknown algorithm")
+
+// Algorithm is the name of a registered hash algorithm
+type Algorithm string
+
+// The built-in hash algorithms, which are registered by default
+const (
+	MD5        Algorithm = "md5"         // MD5, for checksums only
+	SHA1       Algorithm = "sha1"        // SHA-1, for checksums only
+	SHA256     Algorithm = "sha256"      // SHA-256
+	SHA384     Algorithm = "sha384"      // SHA-384
+	SHA512     Algorithm = "sha512"      // SHA-512
+	SHA3_256   Algorithm = "sha3-256"    // SHA3-256
+	SHA3_512   Algorithm = "sha3-512"    // SHA3-512
+	BLAKE2b256 Algorithm = "blake2b-256" // BLAKE2b with a 256 bit digest
+	BLAKE2b512 Algorithm = "blake2b-512" // BLAKE2b with a 512 bit digest
+	CRC32      Algorithm = "crc32"       // CRC-32 of the IEEE polynomial, not cryptographic
+	FNV64a     Algorithm = "fnv64a"      // 64 bit FNV-1a, not cryptographic
+	XXH64      Algorithm = "xxh64"       // 64 bit xxHash, not cryptographic
+)
+
+var (
+	registryMu sync.RWMutex
+	registry   = map[Algorithm]func() gohash.Hash{
+		MD5:        md5.New,
+		SHA1:       sha1.New,
+		SHA256:     sha256.New,
+		SHA384:     sha512.New384,
+		SHA512:     sha512.New,
+		SHA3_256:   sha3.New256,
+		SHA3_512:   sha3.New512,
+		BLAKE2b256: func() gohash.Hash { h, _ := blake2b.New256(nil); return h },
+		BLAKE2b512: func() gohash.Hash { h, _ := blake2b.New512(nil); return h },
+		CRC32:      func() gohash.Hash { return crc32.NewIEEE() },
+		FNV64a:     func() gohash.Hash { return fnv.New64a() },
+		XXH64:      func() gohash.Hash { return xxhash.New() },
+	}
+)
+
+// Register adds or replaces the hash algorithm in the registry
+func Register(alg Algorithm, newHash func() gohash.Hash) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	registry[alg] = newHash
+}
+
+// Algorithms returns the sorted names of the registered hash algorithms
+func Algorithms() []Algorithm {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+	algs := make([]Algorithm, 0, len(registry))
+	for alg := range registry {
+		algs = append(algs, alg)
+	}
+	sort.Slice(algs, func(i, j int) bool { return algs[i] < algs[j] })
+	return algs
+}
+
+// New creates a hash of the registered algorithm
+func New(alg Algorithm) (gohash.Hash, error) {
+	registryMu.RLock()
+	newHash, ok := registry[alg]
+	registryMu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("%w, %s", ErrUnknownAlgorithm, alg)
+	}
+	return newHash(), nil
+}
+
+// Encoding is the text encoding of the digest
+type Encoding int
+
+// The text encodings of the digest
+const (
+	Hex       Encoding = iota // lowercase hex
+	Base64                    // standard base64 with padding
+	Base64URL                 // url safe base64 with padding
+	Base32                    // standard base32 with padding
+)
+
+// Digest is the output of the hash
+type Digest []byte
+
+// Encode encodes the digest into text
+func (d Digest) Encode(enc Encoding) string {
+	switch enc {
+	case Base64:
+		return base64.StdEncoding.EncodeToString(d)
+	case Base64URL:
+		return base64.URLEncoding.EncodeToString(d)
+	case Base32:
+		return base32.StdEncoding.EncodeToString(d)
+	default:
+		return hex.EncodeToString(d)
+	}
+}
+
+// String returns the digest in hex format
+func (d Digest) String() string {
+	return d.Encode(Hex)
+}
+
+// Sum hashes the data with the algorithm
+func Sum(alg Algorithm, data []byte) (Digest, error) {
+	h, err := New(alg)
+	if err != nil {
+		return nil, err
+	}
+	h.Write(data)
+	return h.Sum(nil), nil
+}
+
+// HashReader hashes the data read from r with the algorithm in streaming
+func HashReader(alg Algorithm, r io.Reader) (Digest, error) {
+	digests, err := MultiHashReader(r, alg)
+	if err != nil {
+		return nil, err
+	}
+	return digests[alg], nil
+}
+
+// HashFile hashes the file content with the algorithm in streaming
+func HashFile(alg Algorithm, path string) (Digest, error) {
+	digests, err := MultiHashFile(path, alg)
+	if err != nil {
+		return nil, err
+	}
+	return digests[alg], nil
+}
+
+// MultiHashReader hashes the data read from r with all the algorithms in one pass
+func MultiHashReader(r io.Reader, algs ...Algorithm) (digests map[Algorithm]Digest, err error) {
+	hashes := make(map[Algorithm]gohash.Hash, len(algs))
+	writers := make([]io.Writer, 0, len(algs))
+	for _, alg := range algs {
+		if _, ok := hashes[alg]; ok {
+			continue
+		}
+		h, newErr := New(alg)
+		if newErr != nil {
+			err = newErr
+			return
+		}
+		hashes[alg] = h
+		writers = append(writers, h)
+	}
+	if _, err = io.Copy(io.MultiWriter(writers...), r); err != nil {
+		return
+	}
+	digests = make(map[Algorithm]Digest, len(hashes))
+	for alg, h := range hashes {
+		digests[alg] = h.Sum(nil)
+	}
+	return
+}
+
+// MultiHashFile hashes the file content with all the algorithms in one pass
+func MultiHashFile(path string, algs ...Algorithm) (map[Algorithm]Digest, error) {
+	fh, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer fh.Close()
+	return MultiHashReader(fh, algs...)
+}
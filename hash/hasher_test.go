@@ -0,0 +1,67 @@
+package hash
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestSum(t *testing.T) {
+	samples := map[Algorithm]string{
+		MD5:        "5d41402abc4b2a76b9719d911017c592",
+		SHA1:       "aaf4c61ddcc5e8a2dabede0f3b482cd9aea9434d",
+		SHA256:     "2cf24dba5fb0a30e26e83b2ac5b9e29e1b161e5c1fa7425e73043362938b9824",
+		SHA3_256:   "3338be694f50c5f338814986cdf0686453a888b84f424d792af4b9202398f392",
+		BLAKE2b256: "324dcf027dd4a30a932c441f365a25e86b173defa4b8e58948253471b81b72cf",
+		CRC32:      "3610a686",
+		XXH64:      "26c7827d889f6da3",
+	}
+	for alg, expected := range samples {
+		digest, err := Sum(alg, []byte("hello"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if digest.String() != expected {
+			t.Fatalf("invalid %s hash, got %s", alg, digest)
+		}
+	}
+	if _, err := Sum("md4", nil); !errors.Is(err, ErrUnknownAlgorithm) {
+		t.Fatalf("expect ErrUnknownAlgorithm, got %v", err)
+	}
+}
+
+func TestDigestEncode(t *testing.T) {
+	digest := Digest{0xfb, 0xff, 0x01}
+	samples := map[Encoding]string{
+		Hex:       "fbff01",
+		Base64:    "+/8B",
+		Base64URL: "-_8B",
+		Base32:    "7P7QC===",
+	}
+	for enc, expected := range samples {
+		if digest.Encode(enc) != expected {
+			t.Fatalf("invalid encoding %d, got %s", enc, digest.Encode(enc))
+		}
+	}
+}
+
+func TestMultiHash(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "hello.txt")
+	os.WriteFile(file, []byte("hello"), 0644)
+	digests, err := MultiHashFile(file, MD5, SHA1, SHA256, SHA256)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(digests) != 3 {
+		t.Fatalf("expect 3 digests, got %d", len(digests))
+	}
+	if digests[MD5].String() != Md5HexString([]byte("hello")) || digests[SHA256].String() != Sha256HexString([]byte("hello")) {
+		t.Fatal("multi hash should match the single hash")
+	}
+	digest, err := HashReader(SHA1, strings.NewReader("hello"))
+	if err != nil || digest.String() != Sha1HexString([]byte("hello")) {
+		t.Fatalf("invalid hash reader, %v", err)
+	}
+}
@@ -0,0 +1,167 @@
+package manifest
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/duoland/base/container/tree"
+	"github.com/duoland/base/hash"
+)
+
+// ErrInvalidChecksumLine is returned when the sha256sum line can not be parsed
+var ErrInvalidChecksumLine = errors.New("manifest: invalid checksum line")
+
+// Entry is the checksum record of a regular file
+type Entry struct {
+	Path   string      `json:"path"` // slash separated path relative to the root
+	Size   int64       `json:"size"` // -1 when unknown, such as parsed from sha256sum format
+	Mode   fs.FileMode `json:"mode"` // 0 when unknown
+	SHA256 string      `json:"sha256"`
+}
+
+// Manifest is the list of file checksums of a directory tree, sorted by path
+type Manifest struct {
+	Entries []Entry `json:"entries"`
+}
+
+// Generate walks the directory and creates the manifest of all regular files
+func Generate(root string) (*Manifest, error) {
+	return GenerateFS(os.DirFS(root))
+}
+
+// GenerateFS walks the file system and creates the manifest of all regular files
+func GenerateFS(fsys fs.FS) (m *Manifest, err error) {
+	m = &Manifest{Entries: make([]Entry, 0)}
+	err = fs.WalkDir(fsys, ".", func(path string, d fs.DirEntry, walkErr error) error {
+		if walkErr != nil {
+			return walkErr
+		}
+		if !d.Type().IsRegular() {
+			return nil
+		}
+		info, statErr := d.Info()
+		if statErr != nil {
+			return statErr
+		}
+		fh, openErr := fsys.Open(path)
+		if openErr != nil {
+			return openErr
+		}
+		defer fh.Close()
+		digest, hashErr := hash.HashReader(hash.SHA256, fh)
+		if hashErr != nil {
+			return fmt.Errorf("hash file %s error, %s", path, hashErr.Error())
+		}
+		m.Entries = append(m.Entries, Entry{
+			Path:   path,
+			Size:   info.Size(),
+			Mode:   info.Mode().Perm(),
+			SHA256: digest.String(),
+		})
+		return nil
+	})
+	if err != nil {
+		m = nil
+		return
+	}
+	m.sort()
+	return
+}
+
+func (m *Manifest) sort() {
+	sort.Slice(m.Entries, func(i, j int) bool { return m.Entries[i].Path < m.Entries[j].Path })
+}
+
+// Paths returns the sorted file paths in the manifest
+func (m *Manifest) Paths() []string {
+	paths := make([]string, 0, len(m.Entries))
+	for _, entry := range m.Entries {
+		paths = append(paths, entry.Path)
+	}
+	return paths
+}
+
+// FileTree creates the file tree layout of the manifest
+func (m *Manifest) FileTree() tree.FileTreeNodes {
+	return tree.CreateFileTreeLayout(m.Paths())
+}
+
+// WriteSHA256Sum writes the manifest in the format of sha256sum(1), which can be checked by `sha256sum -c`
+func (m *Manifest) WriteSHA256Sum(w io.Writer) error {
+	bw := bufio.NewWriter(w)
+	for _, entry := range m.Entries {
+		// escape the file name as sha256sum does
+		path := entry.Path
+		if strings.ContainsAny(path, "\\\n") {
+			path = strings.NewReplacer("\\", "\\\\", "\n", "\\n").Replace(path)
+			bw.WriteString("\\")
+		}
+		bw.WriteString(entry.SHA256)
+		bw.WriteString("  ")
+		bw.WriteString(path)
+		bw.WriteString("\n")
+	}
+	return bw.Flush()
+}
+
+// ParseSHA256Sum reads the manifest in the format of sha256sum(1), the size and mode are unknown
+func ParseSHA256Sum(r io.Reader) (m *Manifest, err error) {
+	m = &Manifest{Entries: make([]Entry, 0)}
+	scanner := bufio.NewScanner(r)
+	lineNo := 0
+	for scanner.Scan() {
+		lineNo++
+		line := scanner.Text()
+		if line == "" {
+			continue
+		}
+		escaped := strings.HasPrefix(line, "\\")
+		if escaped {
+			line = line[1:]
+		}
+		// the separator is two spaces in text mode and space star in binary mode
+		if len(line) < 66 || (line[64:66] != "  " && line[64:66] != " *") {
+			err = fmt.Errorf("%w, line %d", ErrInvalidChecksumLine, lineNo)
+			return
+		}
+		path := line[66:]
+		if escaped {
+			path = strings.NewReplacer("\\\\", "\\", "\\n", "\n").Replace(path)
+		}
+		m.Entries = append(m.Entries, Entry{
+			Path:   strings.TrimPrefix(path, "./"),
+			Size:   -1,
+			SHA256: strings.ToLower(line[:64]),
+		})
+	}
+	if err = scanner.Err(); err != nil {
+		return
+	}
+	m.sort()
+	return
+}
+
+// WriteJSON writes the manifest in json format
+func (m *Manifest) WriteJSON(w io.Writer) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(m)
+}
+
+// ParseJSON reads the manifest in json format
+func ParseJSON(r io.Reader) (m *Manifest, err error) {
+	m = &Manifest{}
+	if err = json.NewDecoder(r).Decode(m); err != nil {
+		m = nil
+		return
+	}
+	m.sort()
+	return
+}
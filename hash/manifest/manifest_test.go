@@ -0,0 +1,107 @@
+package manifest
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/duoland/base/container/tree"
+)
+
+func writeFiles(t *testing.T, root string, files map[string]string) {
+	for path, content := range files {
+		fullPath := filepath.Join(root, filepath.FromSlash(path))
+		os.MkdirAll(filepath.Dir(fullPath), 0755)
+		if err := os.WriteFile(fullPath, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestGenerateAndVerify(t *testing.T) {
+	root := t.TempDir()
+	writeFiles(t, root, map[string]string{
+		"bin/app":         "app v1",
+		"conf/app.yaml":   "port: 8080",
+		"conf/db.yaml":    "host: db",
+		"static/logo.svg": "<svg/>",
+	})
+	m, err := Generate(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(m.Paths(), []string{"bin/app", "conf/app.yaml", "conf/db.yaml", "static/logo.svg"}) {
+		t.Fatalf("unexpected paths %v", m.Paths())
+	}
+	if report, _ := Verify(root, m); !report.OK() {
+		t.Fatalf("tree should match the manifest, %+v", report)
+	}
+
+	writeFiles(t, root, map[string]string{"bin/app": "app v2", "conf/cache.yaml": "size: 1"})
+	os.Remove(filepath.Join(root, "conf", "db.yaml"))
+	report, err := Verify(root, m)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(report.Added, []string{"conf/cache.yaml"}) ||
+		!reflect.DeepEqual(report.Removed, []string{"conf/db.yaml"}) ||
+		!reflect.DeepEqual(report.Modified, []string{"bin/app"}) {
+		t.Fatalf("unexpected report %+v", report)
+	}
+	fileTree := report.FileTree()
+	if len(fileTree) != 2 || fileTree[0].Title != "bin" || len(*fileTree[1].Children) != 2 {
+		t.Fatalf("unexpected file tree %+v", fileTree)
+	}
+	conf := *fileTree[1].Children
+	if fileTree[0].Status != tree.StatusModified || (*fileTree[0].Children)[0].Status != tree.StatusModified ||
+		conf[0].Key != "conf/cache.yaml" || conf[0].Status != tree.StatusAdded || conf[1].Status != tree.StatusRemoved {
+		t.Fatalf("unexpected statuses %+v", fileTree)
+	}
+}
+
+func TestSHA256SumFormat(t *testing.T) {
+	root := t.TempDir()
+	writeFiles(t, root, map[string]string{"hello.txt": "hello", "a\\b.txt": "world"})
+	m, _ := Generate(root)
+
+	var buf bytes.Buffer
+	if err := m.WriteSHA256Sum(&buf); err != nil {
+		t.Fatal(err)
+	}
+	expected := "\\486ea46224d1bb4fb680f34f7c9ad96a8f24ec88be73ea8e5a6c65260e9cb8a7  a\\\\b.txt\n" +
+		"2cf24dba5fb0a30e26e83b2ac5b9e29e1b161e5c1fa7425e73043362938b9824  hello.txt\n"
+	if buf.String() != expected {
+		t.Fatalf("unexpected sha256sum output\n%s", buf.String())
+	}
+
+	parsed, err := ParseSHA256Sum(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report := Compare(parsed, m); !report.OK() {
+		t.Fatalf("parsed manifest should match, %+v", report)
+	}
+	if _, err := ParseSHA256Sum(bytes.NewBufferString("abc  hello.txt\n")); err == nil {
+		t.Fatal("invalid line should fail")
+	}
+}
+
+func TestJSONFormat(t *testing.T) {
+	root := t.TempDir()
+	writeFiles(t, root, map[string]string{"hello.txt": "hello"})
+	m, _ := Generate(root)
+
+	var buf bytes.Buffer
+	if err := m.WriteJSON(&buf); err != nil {
+		t.Fatal(err)
+	}
+	parsed, err := ParseJSON(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(parsed, m) {
+		t.Fatalf("json round trip mismatch, %+v", parsed)
+	}
+}
@@ -0,0 +1,111 @@
+package manifest
+
+import (
+	"io/fs"
+	"os"
+	"sort"
+
+	"github.com/duoland/base/container/tree"
+)
+
+// Report is the result of verifying a directory tree against a manifest
+type Report struct {
+	Added    []string `json:"added"`    // files in the tree but not in the manifest
+	Removed  []string `json:"removed"`  // files in the manifest but not in the tree
+	Modified []string `json:"modified"` // files with different checksum, size or mode
+}
+
+// OK returns true when the tree matches the manifest
+func (r *Report) OK() bool {
+	return len(r.Added) == 0 && len(r.Removed) == 0 && len(r.Modified) == 0
+}
+
+// Paths returns all the sorted paths in the report
+func (r *Report) Paths() []string {
+	paths := make([]string, 0, len(r.Added)+len(r.Removed)+len(r.Modified))
+	paths = append(paths, r.Added...)
+	paths = append(paths, r.Removed...)
+	paths = append(paths, r.Modified...)
+	sort.Strings(paths)
+	return paths
+}
+
+// FileTree creates the file tree layout of all the paths in the report, the files have the
+// Status of added, removed or modified, and the directories containing them are modified
+func (r *Report) FileTree() tree.FileTreeNodes {
+	statuses := make(map[string]tree.DiffStatus, len(r.Added)+len(r.Removed)+len(r.Modified))
+	for _, p := range r.Added {
+		statuses[p] = tree.StatusAdded
+	}
+	for _, p := range r.Removed {
+		statuses[p] = tree.StatusRemoved
+	}
+	for _, p := range r.Modified {
+		statuses[p] = tree.StatusModified
+	}
+	nodes := tree.CreateFileTreeLayout(r.Paths())
+	setStatuses(nodes, statuses)
+	return nodes
+}
+
+func setStatuses(nodes tree.FileTreeNodes, statuses map[string]tree.DiffStatus) {
+	for i := range nodes {
+		node := &nodes[i]
+		if node.IsLeaf {
+			node.Status = statuses[node.Key]
+			continue
+		}
+		node.Status = tree.StatusModified
+		if node.Children != nil {
+			setStatuses(*node.Children, statuses)
+		}
+	}
+}
+
+// Verify checks the directory against the manifest
+func Verify(root string, m *Manifest) (*Report, error) {
+	return VerifyFS(os.DirFS(root), m)
+}
+
+// VerifyFS checks the file system against the manifest
+func VerifyFS(fsys fs.FS, m *Manifest) (*Report, error) {
+	actual, err := GenerateFS(fsys)
+	if err != nil {
+		return nil, err
+	}
+	return Compare(m, actual), nil
+}
+
+// Compare reports the differences from the expected manifest to the actual one,
+// the size and mode are only compared when they are known in both manifests.
+func Compare(expected, actual *Manifest) *Report {
+	report := &Report{
+		Added:    make([]string, 0),
+		Removed:  make([]string, 0),
+		Modified: make([]string, 0),
+	}
+	expectedEntries := make(map[string]Entry, len(expected.Entries))
+	for _, entry := range expected.Entries {
+		expectedEntries[entry.Path] = entry
+	}
+	for _, entry := range actual.Entries {
+		expectedEntry, ok := expectedEntries[entry.Path]
+		if !ok {
+			report.Added = append(report.Added, entry.Path)
+			continue
+		}
+		delete(expectedEntries, entry.Path)
+		if expectedEntry.SHA256 != entry.SHA256 ||
+			(expectedEntry.Size >= 0 && entry.Size >= 0 && expectedEntry.Size != entry.Size) ||
+			(expectedEntry.Mode != 0 && entry.Mode != 0 && expectedEntry.Mode != entry.Mode) {
+			report.Modified = append(report.Modified, entry.Path)
+		}
+	}
+	for path := range expectedEntries {
+		report.Removed = append(report.Removed, path)
+	}
+	sort.Strings(report.Added)
+	sort.Strings(report.Removed)
+	sort.Strings(report.Modified)
+	return report
+}
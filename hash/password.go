@@ -0,0 +1,369 @@
+package hash
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/sha512"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	gohash "hash"
+	"strconv"
+	"strings"
+
+	"golang.org/x/crypto/argon2"
+	"golang.org/x/crypto/pbkdf2"
+	"golang.org/x/crypto/scrypt"
+)
+
+var (
+	// ErrInvalidPasswordHash is returned when the encoded hash is not in PHC format
+	ErrInvalidPasswordHash = errors.New("hash: invalid password hash")
+
+	// ErrUnsupportedPasswordHash is returned when the encoded hash algorithm is unknown
+	ErrUnsupportedPasswordHash = errors.New("hash: unsupported password hash algorithm")
+)
+
+// The max params accepted by ParsePasswordHash, so a planted hash can not exhaust the verifier
+const (
+	MaxPasswordMemory      = 1 << 20 // in KiB, the memory of argon2id and scrypt
+	MaxArgon2idIterations  = 64
+	MaxArgon2idParallelism = 64
+	MaxScryptRP            = 1 << 10 // the product of r and p
+	MaxPBKDF2Iterations    = 10000000
+	MaxPasswordSaltLength  = 64
+	MaxPasswordKeyLength   = 128
+)
+
+// phcEncoding is the base64 encoding used by the PHC string format
+var phcEncoding = base64.RawStdEncoding
+
+// PasswordParams is the parameters of a password-based key derivation function
+type PasswordParams interface {
+	// Algorithm returns the PHC identifier of the function, such as argon2id
+	Algorithm() string
+	// DeriveKey derives the key from the password and salt
+	DeriveKey(password, salt []byte) ([]byte, error)
+
+	saltLength() uint32
+	encodeParams() string
+	weakerThan(target PasswordParams) bool
+}
+
+// Argon2idParams is the parameters of argon2id
+type Argon2idParams struct {
+	Memory      uint32 // in KiB
+	Iterations  uint32
+	Parallelism uint8
+	SaltLength  uint32
+	KeyLength   uint32
+}
+
+// DefaultArgon2idParams is the recommended parameters of argon2id
+var DefaultArgon2idParams = &Argon2idParams{
+	Memory:      64 * 1024,
+	Iterations:  3,
+	Parallelism: 2,
+	SaltLength:  16,
+	KeyLength:   32,
+}
+
+// Algorithm implements PasswordParams
+func (p *Argon2idParams) Algorithm() string {
+	return "argon2id"
+}
+
+// DeriveKey implements PasswordParams
+func (p *Argon2idParams) DeriveKey(password, salt []byte) ([]byte, error) {
+	if p.Memory == 0 || p.Iterations == 0 || p.Parallelism == 0 || p.KeyLength == 0 {
+		return nil, fmt.Errorf("hash: invalid argon2id params %s", p.encodeParams())
+	}
+	return argon2.IDKey(password, salt, p.Iterations, p.Memory, p.Parallelism, p.KeyLength), nil
+}
+
+func (p *Argon2idParams) saltLength() uint32 {
+	return p.SaltLength
+}
+
+func (p *Argon2idParams) encodeParams() string {
+	return fmt.Sprintf("v=%d$m=%d,t=%d,p=%d", argon2.Version, p.Memory, p.Iterations, p.Parallelism)
+}
+
+func (p *Argon2idParams) weakerThan(target PasswordParams) bool {
+	t := target.(*Argon2idParams)
+	return p.Memory < t.Memory || p.Iterations < t.Iterations || p.Parallelism < t.Parallelism ||
+		p.SaltLength < t.SaltLength || p.KeyLength < t.KeyLength
+}
+
+// ScryptParams is the parameters of scrypt, N is 2^LogN
+type ScryptParams struct {
+	LogN       uint8
+	R          int
+	P          int
+	SaltLength uint32
+	KeyLength  uint32
+}
+
+// DefaultScryptParams is the recommended parameters of scrypt
+var DefaultScryptParams = &ScryptParams{
+	LogN:       15,
+	R:          8,
+	P:          1,
+	SaltLength: 16,
+	KeyLength:  32,
+}
+
+// Algorithm implements PasswordParams
+func (p *ScryptParams) Algorithm() string {
+	return "scrypt"
+}
+
+// DeriveKey implements PasswordParams
+func (p *ScryptParams) DeriveKey(password, salt []byte) ([]byte, error) {
+	if p.LogN == 0 || p.LogN > 30 {
+		return nil, fmt.Errorf("hash: invalid scrypt params %s", p.encodeParams())
+	}
+	return scrypt.Key(password, salt, 1<<p.LogN, p.R, p.P, int(p.KeyLength))
+}
+
+func (p *ScryptParams) saltLength() uint32 {
+	return p.SaltLength
+}
+
+func (p *ScryptParams) encodeParams() string {
+	return fmt.Sprintf("ln=%d,r=%d,p=%d", p.LogN, p.R, p.P)
+}
+
+func (p *ScryptParams) weakerThan(target PasswordParams) bool {
+	t := target.(*ScryptParams)
+	return p.LogN < t.LogN || p.R < t.R || p.P < t.P ||
+		p.SaltLength < t.SaltLength || p.KeyLength < t.KeyLength
+}
+
+// PBKDF2Params is the parameters of pbkdf2, the digest is sha256 or sha512
+type PBKDF2Params struct {
+	Digest     string
+	Iterations int
+	SaltLength uint32
+	KeyLength  uint32
+}
+
+// DefaultPBKDF2Params is the recommended parameters of pbkdf2
+var DefaultPBKDF2Params = &PBKDF2Params{
+	Digest:     "sha256",
+	Iterations: 600000,
+	SaltLength: 16,
+	KeyLength:  32,
+}
+
+// Algorithm implements PasswordParams
+func (p *PBKDF2Params) Algorithm() string {
+	return "pbkdf2-" + p.Digest
+}
+
+// DeriveKey implements PasswordParams
+func (p *PBKDF2Params) DeriveKey(password, salt []byte) ([]byte, error) {
+	var h func() gohash.Hash
+	switch p.Digest {
+	case "sha256":
+		h = sha256.New
+	case "sha512":
+		h = sha512.New
+	default:
+		return nil, ErrUnsupportedPasswordHash
+	}
+	if p.Iterations <= 0 || p.KeyLength == 0 {
+		return nil, fmt.Errorf("hash: invalid pbkdf2 params %s", p.encodeParams())
+	}
+	return pbkdf2.Key(password, salt, p.Iterations, int(p.KeyLength), h), nil
+}
+
+func (p *PBKDF2Params) saltLength() uint32 {
+	return p.SaltLength
+}
+
+func (p *PBKDF2Params) encodeParams() string {
+	return fmt.Sprintf("i=%d", p.Iterations)
+}
+
+func (p *PBKDF2Params) weakerThan(target PasswordParams) bool {
+	t := target.(*PBKDF2Params)
+	return p.Iterations < t.Iterations || p.SaltLength < t.SaltLength || p.KeyLength < t.KeyLength
+}
+
+// GenerateSalt creates a random salt of the specified size
+func GenerateSalt(size uint32) ([]byte, error) {
+	salt := make([]byte, size)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, fmt.Errorf("hash: generate salt error, %s", err.Error())
+	}
+	return salt, nil
+}
+
+// DeriveKey derives an encryption key from the password, such as the key used by the crypto package
+func DeriveKey(password, salt []byte, params PasswordParams) ([]byte, error) {
+	return params.DeriveKey(password, salt)
+}
+
+// PasswordHasher hashes passwords into PHC format strings with the preferred params
+type PasswordHasher struct {
+	Params PasswordParams
+}
+
+// NewPasswordHasher creates a password hasher with the preferred params
+func NewPasswordHasher(params PasswordParams) *PasswordHasher {
+	return &PasswordHasher{Params: params}
+}
+
+// DefaultPasswordHasher hashes passwords with argon2id
+var DefaultPasswordHasher = NewPasswordHasher(DefaultArgon2idParams)
+
+// Hash hashes the password with a random salt, and returns the PHC format string like
+//
+//	$argon2id$v=19$m=65536,t=3,p=2$<salt>$<hash>
+func (h *PasswordHasher) Hash(password string) (encodedHash string, err error) {
+	salt, err := GenerateSalt(h.Params.saltLength())
+	if err != nil {
+		return
+	}
+	key, err := h.Params.DeriveKey([]byte(password), salt)
+	if err != nil {
+		return
+	}
+	encodedHash = fmt.Sprintf("$%s$%s$%s$%s", h.Params.Algorithm(), h.Params.encodeParams(),
+		phcEncoding.EncodeToString(salt), phcEncoding.EncodeToString(key))
+	return
+}
+
+// Verify checks the password against the encoded hash in constant time, and reports
+// whether the hash needs upgrading because of a different algorithm or weaker params.
+func (h *PasswordHasher) Verify(password, encodedHash string) (match, needsUpgrade bool, err error) {
+	params, salt, key, err := ParsePasswordHash(encodedHash)
+	if err != nil {
+		return
+	}
+	derivedKey, err := params.DeriveKey([]byte(password), salt)
+	if err != nil {
+		return
+	}
+	match = subtle.ConstantTimeCompare(derivedKey, key) == 1
+	needsUpgrade = params.Algorithm() != h.Params.Algorithm() || params.weakerThan(h.Params)
+	return
+}
+
+// HashPassword hashes the password with the default password hasher
+func HashPassword(password string) (string, error) {
+	return DefaultPasswordHasher.Hash(password)
+}
+
+// VerifyPassword checks the password with the default password hasher
+func VerifyPassword(password, encodedHash string) (match, needsUpgrade bool, err error) {
+	return DefaultPasswordHasher.Verify(password, encodedHash)
+}
+
+// ParsePasswordHash decodes the PHC format string into params, salt and key
+func ParsePasswordHash(encodedHash string) (params PasswordParams, salt, key []byte, err error) {
+	parts := strings.Split(encodedHash, "$")
+	if len(parts) < 5 || parts[0] != "" {
+		err = ErrInvalidPasswordHash
+		return
+	}
+	alg, paramParts := parts[1], parts[2:len(parts)-2]
+	if salt, err = phcEncoding.DecodeString(parts[len(parts)-2]); err != nil {
+		err = ErrInvalidPasswordHash
+		return
+	}
+	if key, err = phcEncoding.DecodeString(parts[len(parts)-1]); err != nil || len(key) == 0 {
+		err = ErrInvalidPasswordHash
+		return
+	}
+	if len(salt) > MaxPasswordSaltLength || len(key) > MaxPasswordKeyLength {
+		err = ErrInvalidPasswordHash
+		return
+	}
+	saltLength, keyLength := uint32(len(salt)), uint32(len(key))
+
+	switch {
+	case alg == "argon2id":
+		if len(paramParts) != 2 || paramParts[0] != fmt.Sprintf("v=%d", argon2.Version) {
+			err = ErrInvalidPasswordHash
+			return
+		}
+		values, parseErr := parsePHCParams(paramParts[1], "m", "t", "p")
+		if parseErr != nil || values[0] > MaxPasswordMemory || values[1] > MaxArgon2idIterations ||
+			values[2] > MaxArgon2idParallelism {
+			err = ErrInvalidPasswordHash
+			return
+		}
+		params = &Argon2idParams{
+			Memory:      uint32(values[0]),
+			Iterations:  uint32(values[1]),
+			Parallelism: uint8(values[2]),
+			SaltLength:  saltLength,
+			KeyLength:   keyLength,
+		}
+	case alg == "scrypt":
+		if len(paramParts) != 1 {
+			err = ErrInvalidPasswordHash
+			return
+		}
+		values, parseErr := parsePHCParams(paramParts[0], "ln", "r", "p")
+		// the memory of scrypt is 128*r*N bytes
+		if parseErr != nil || values[0] == 0 || values[0] > 30 || values[1] == 0 || values[2] == 0 ||
+			values[1]*values[2] > MaxScryptRP || 128*values[1]<<values[0] > MaxPasswordMemory*1024 {
+			err = ErrInvalidPasswordHash
+			return
+		}
+		params = &ScryptParams{
+			LogN:       uint8(values[0]),
+			R:          int(values[1]),
+			P:          int(values[2]),
+			SaltLength: saltLength,
+			KeyLength:  keyLength,
+		}
+	case strings.HasPrefix(alg, "pbkdf2-"):
+		if len(paramParts) != 1 {
+			err = ErrInvalidPasswordHash
+			return
+		}
+		values, parseErr := parsePHCParams(paramParts[0], "i")
+		if parseErr != nil || values[0] > MaxPBKDF2Iterations {
+			err = ErrInvalidPasswordHash
+			return
+		}
+		params = &PBKDF2Params{
+			Digest:     strings.TrimPrefix(alg, "pbkdf2-"),
+			Iterations: int(values[0]),
+			SaltLength: saltLength,
+			KeyLength:  keyLength,
+		}
+	default:
+		err = ErrUnsupportedPasswordHash
+	}
+	return
+}
+
+// parsePHCParams parses the params like m=65536,t=3,p=2 in the specified order
+func parsePHCParams(encoded string, names ...string) (values []uint64, err error) {
+	items := strings.Split(encoded, ",")
+	if len(items) != len(names) {
+		err = ErrInvalidPasswordHash
+		return
+	}
+	values = make([]uint64, 0, len(names))
+	for i, item := range items {
+		name, value, found := strings.Cut(item, "=")
+		if !found || name != names[i] {
+			err = ErrInvalidPasswordHash
+			return
+		}
+		v, parseErr := strconv.ParseUint(value, 10, 32)
+		if parseErr != nil {
+			err = ErrInvalidPasswordHash
+			return
+		}
+		values = append(values, v)
+	}
+	return
+}
@@ -0,0 +1,89 @@
+package hash
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+)
+
+// light params to keep the tests fast
+var (
+	testArgon2idParams = &Argon2idParams{Memory: 1024, Iterations: 1, Parallelism: 1, SaltLength: 16, KeyLength: 32}
+	testScryptParams   = &ScryptParams{LogN: 10, R: 8, P: 1, SaltLength: 16, KeyLength: 32}
+	testPBKDF2Params   = &PBKDF2Params{Digest: "sha256", Iterations: 1000, SaltLength: 16, KeyLength: 32}
+)
+
+func TestHashAndVerifyPassword(t *testing.T) {
+	for _, params := range []PasswordParams{testArgon2idParams, testScryptParams, testPBKDF2Params} {
+		hasher := NewPasswordHasher(params)
+		encodedHash, err := hasher.Hash("p@ssw0rd")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !strings.HasPrefix(encodedHash, "$"+params.Algorithm()+"$") {
+			t.Fatalf("invalid PHC string %s", encodedHash)
+		}
+
+		match, needsUpgrade, err := hasher.Verify("p@ssw0rd", encodedHash)
+		if err != nil || !match || needsUpgrade {
+			t.Fatalf("%s verify failed, match=%v, needsUpgrade=%v, err=%v", params.Algorithm(), match, needsUpgrade, err)
+		}
+		if match, _, _ = hasher.Verify("password", encodedHash); match {
+			t.Fatalf("%s should not match wrong password", params.Algorithm())
+		}
+	}
+}
+
+func TestVerifyPasswordNeedsUpgrade(t *testing.T) {
+	weakHash, _ := NewPasswordHasher(testPBKDF2Params).Hash("p@ssw0rd")
+	hasher := NewPasswordHasher(testArgon2idParams)
+	match, needsUpgrade, err := hasher.Verify("p@ssw0rd", weakHash)
+	if err != nil || !match || !needsUpgrade {
+		t.Fatalf("pbkdf2 hash should need upgrading to argon2id, match=%v, needsUpgrade=%v, err=%v", match, needsUpgrade, err)
+	}
+
+	stronger := *testArgon2idParams
+	stronger.Iterations = 2
+	oldHash, _ := hasher.Hash("p@ssw0rd")
+	if _, needsUpgrade, _ = NewPasswordHasher(&stronger).Verify("p@ssw0rd", oldHash); !needsUpgrade {
+		t.Fatal("argon2id hash with less iterations should need upgrading")
+	}
+}
+
+func TestParsePasswordHash(t *testing.T) {
+	// RFC 9106 style argon2id hash
+	params, _, _, err := ParsePasswordHash("$argon2id$v=19$m=65536,t=3,p=4$c29tZXNhbHQ$RdescudvJCsgt3ub+b+dWRWJTmaaJObG")
+	if err != nil {
+		t.Fatal(err)
+	}
+	argon2idParams := params.(*Argon2idParams)
+	if argon2idParams.Memory != 65536 || argon2idParams.Iterations != 3 || argon2idParams.Parallelism != 4 {
+		t.Fatalf("invalid argon2id params %+v", argon2idParams)
+	}
+
+	for _, encodedHash := range []string{"", "argon2id", "$argon2id$v=18$m=1,t=1,p=1$c2FsdA$a2V5", "$scrypt$ln=x,r=8,p=1$c2FsdA$a2V5",
+		"$argon2id$v=19$m=4294967295,t=1,p=1$c2FsdA$a2V5", "$argon2id$v=19$m=1024,t=100000,p=1$c2FsdA$a2V5",
+		"$argon2id$v=19$m=1024,t=1,p=255$c2FsdA$a2V5", "$scrypt$ln=30,r=8,p=1$c2FsdA$a2V5",
+		"$scrypt$ln=10,r=4294967295,p=1$c2FsdA$a2V5", "$scrypt$ln=10,r=8,p=4294967295$c2FsdA$a2V5",
+		"$pbkdf2-sha256$i=4294967295$c2FsdA$a2V5"} {
+		if _, _, _, err := ParsePasswordHash(encodedHash); !errors.Is(err, ErrInvalidPasswordHash) {
+			t.Fatalf("expect ErrInvalidPasswordHash for %q, got %v", encodedHash, err)
+		}
+	}
+	if _, _, _, err := ParsePasswordHash("$bcrypt$x$c2FsdA$a2V5"); !errors.Is(err, ErrUnsupportedPasswordHash) {
+		t.Fatalf("expect ErrUnsupportedPasswordHash, got %v", err)
+	}
+}
+
+func TestDeriveKey(t *testing.T) {
+	salt := []byte("0123456789abcdef")
+	key1, err := DeriveKey([]byte("p@ssw0rd"), salt, testScryptParams)
+	if err != nil {
+		t.Fatal(err)
+	}
+	key2, _ := DeriveKey([]byte("p@ssw0rd"), salt, testScryptParams)
+	if len(key1) != 32 || !bytes.Equal(key1, key2) {
+		t.Fatal("derived key should be deterministic with 32 bytes")
+	}
+}
@@ -0,0 +1,89 @@
+package hooks
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// ErrBulkheadFull is returned when the bulkhead has no free slot in time
+var ErrBulkheadFull = errors.New("hooks: bulkhead is full")
+
+// Bulkhead limits the concurrent calls, the calls over the limit wait in a bounded queue
+type Bulkhead struct {
+	slots        chan struct{}
+	maxQueue     int64
+	queueTimeout time.Duration
+	waiting      atomic.Int64
+}
+
+// NewBulkhead returns a bulkhead allowing maxConcurrent calls, at most maxQueue calls wait
+// for queueTimeout, 0 means waiting until the context is done.
+func NewBulkhead(maxConcurrent, maxQueue int, queueTimeout time.Duration) *Bulkhead {
+	if maxConcurrent <= 0 {
+		panic("hooks: bulkhead max concurrent must be positive")
+	}
+	return &Bulkhead{
+		slots:        make(chan struct{}, maxConcurrent),
+		maxQueue:     int64(max(maxQueue, 0)),
+		queueTimeout: queueTimeout,
+	}
+}
+
+// InFlight returns the number of the running calls
+func (b *Bulkhead) InFlight() int {
+	return len(b.slots)
+}
+
+// Waiting returns the number of the queued calls
+func (b *Bulkhead) Waiting() int {
+	return int(b.waiting.Load())
+}
+
+// Acquire takes a slot, release must be called when the call finishes
+func (b *Bulkhead) Acquire(ctx context.Context) (release func(), err error) {
+	select {
+	case b.slots <- struct{}{}:
+		return b.releaseFunc(), nil
+	default:
+	}
+	if b.waiting.Add(1) > b.maxQueue {
+		b.waiting.Add(-1)
+		return nil, ErrBulkheadFull
+	}
+	defer b.waiting.Add(-1)
+
+	var timeout <-chan time.Time
+	if b.queueTimeout > 0 {
+		timer := time.NewTimer(b.queueTimeout)
+		defer timer.Stop()
+		timeout = timer.C
+	}
+	select {
+	case b.slots <- struct{}{}:
+		return b.releaseFunc(), nil
+	case <-timeout:
+		return nil, ErrBulkheadFull
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+func (b *Bulkhead) releaseFunc() func() {
+	var once sync.Once
+	return func() {
+		once.Do(func() { <-b.slots })
+	}
+}
+
+// Execute calls fn in a slot
+func (b *Bulkhead) Execute(ctx context.Context, fn func(context.Context) error) error {
+	release, err := b.Acquire(ctx)
+	if err != nil {
+		return err
+	}
+	defer release()
+	return fn(ctx)
+}
@@ -0,0 +1,87 @@
+package hooks
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestBulkheadLimit(t *testing.T) {
+	b := NewBulkhead(2, 10, 0)
+	var running, peak atomic.Int32
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			b.Execute(context.Background(), func(ctx context.Context) error {
+				n := running.Add(1)
+				for {
+					p := peak.Load()
+					if n <= p || peak.CompareAndSwap(p, n) {
+						break
+					}
+				}
+				time.Sleep(5 * time.Millisecond)
+				running.Add(-1)
+				return nil
+			})
+		}()
+	}
+	wg.Wait()
+	if peak.Load() != 2 || b.InFlight() != 0 || b.Waiting() != 0 {
+		t.Fatalf("unexpected peak %d", peak.Load())
+	}
+}
+
+func TestBulkheadQueue(t *testing.T) {
+	b := NewBulkhead(1, 1, 20*time.Millisecond)
+	release, err := b.Acquire(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	waitErr := make(chan error)
+	go func() {
+		_, err := b.Acquire(context.Background())
+		waitErr <- err
+	}()
+	for b.Waiting() == 0 {
+		time.Sleep(time.Millisecond)
+	}
+	if _, err := b.Acquire(context.Background()); !errors.Is(err, ErrBulkheadFull) {
+		t.Fatalf("full queue should reject, got %v", err)
+	}
+	if err := <-waitErr; !errors.Is(err, ErrBulkheadFull) {
+		t.Fatalf("queued call should time out, got %v", err)
+	}
+	release()
+	release()
+	if b.InFlight() != 0 {
+		t.Fatal("release should be idempotent")
+	}
+}
+
+func TestGuard(t *testing.T) {
+	breaker := NewCircuitBreaker(BreakerOptions{ConsecutiveFailures: 2, OpenTimeout: time.Hour})
+	g := &Guard{
+		Retry:    NewIntervalPolicy(CreateFixedIntervals(5, time.Millisecond)),
+		Breaker:  breaker,
+		Bulkhead: NewBulkhead(1, 0, 0),
+	}
+	calls := 0
+	err := g.Run(context.Background(), "task", func(ctx context.Context) error {
+		calls++
+		return errTemporary
+	})
+	if !errors.Is(err, ErrCircuitOpen) || calls != 2 {
+		t.Fatalf("open breaker should stop the retry, got %v after %d calls", err, calls)
+	}
+
+	g = &Guard{Breaker: NewCircuitBreaker(BreakerOptions{})}
+	if err := g.Run(context.Background(), "task", succeeding); err != nil {
+		t.Fatal(err)
+	}
+}
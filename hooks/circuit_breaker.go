@@ -0,0 +1,310 @@
+package hooks
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+// ErrCircuitOpen is returned when the circuit breaker rejects the call
+var ErrCircuitOpen = errors.New("hooks: circuit breaker is open")
+
+// BreakerState is the state of the circuit breaker
+type BreakerState int
+
+const (
+	StateClosed   BreakerState = iota // calls pass through and the failures are counted
+	StateOpen                         // calls are rejected until OpenTimeout elapses
+	StateHalfOpen                     // a limited number of probe calls decide to close or open again
+)
+
+func (s BreakerState) String() string {
+	switch s {
+	case StateClosed:
+		return "closed"
+	case StateOpen:
+		return "open"
+	case StateHalfOpen:
+		return "half-open"
+	default:
+		return "unknown"
+	}
+}
+
+// BreakerOptions is the options of the circuit breaker, the zero values take the defaults
+type BreakerOptions struct {
+	Name                string
+	ConsecutiveFailures int                                      // open after the consecutive failures, default 5, -1 disables it
+	FailureRate         float64                                  // open when the failure rate in Window reaches it, 0 disables it
+	MinRequests         int                                      // min requests in Window to apply FailureRate, default 10
+	Window              time.Duration                            // rolling window of FailureRate, default 1 minute
+	Buckets             int                                      // buckets of the rolling window, default 10
+	OpenTimeout         time.Duration                            // time to stay open before half-open, default 30 seconds
+	HalfOpenRequests    int                                      // probe calls allowed in half-open, all must succeed to close, default 1
+	IsFailure           func(err error) bool                     // default all errors except context.Canceled
+	OnStateChange       func(name string, from, to BreakerState) // called without holding the lock
+	Now                 func() time.Time
+}
+
+func (opts *BreakerOptions) setDefaults() {
+	if opts.ConsecutiveFailures == 0 {
+		opts.ConsecutiveFailures = 5
+	}
+	if opts.MinRequests <= 0 {
+		opts.MinRequests = 10
+	}
+	if opts.Window <= 0 {
+		opts.Window = time.Minute
+	}
+	if opts.Buckets <= 0 {
+		opts.Buckets = 10
+	}
+	if opts.OpenTimeout <= 0 {
+		opts.OpenTimeout = 30 * time.Second
+	}
+	if opts.HalfOpenRequests <= 0 {
+		opts.HalfOpenRequests = 1
+	}
+	if opts.IsFailure == nil {
+		opts.IsFailure = func(err error) bool {
+			return err != nil && !errors.Is(err, context.Canceled)
+		}
+	}
+	if opts.Now == nil {
+		opts.Now = time.Now
+	}
+}
+
+// BreakerCounts is the statistics of the current state
+type BreakerCounts struct {
+	Requests            int // requests in the rolling window
+	Failures            int // failures in the rolling window
+	ConsecutiveFailures int
+}
+
+type bucket struct {
+	start              time.Time
+	requests, failures int
+}
+
+// rollingWindow counts the requests in buckets, the outdated buckets are reset when reused
+type rollingWindow struct {
+	buckets []bucket
+	width   time.Duration
+}
+
+func newRollingWindow(window time.Duration, buckets int) *rollingWindow {
+	return &rollingWindow{
+		buckets: make([]bucket, buckets),
+		width:   max(window/time.Duration(buckets), 1),
+	}
+}
+
+func (w *rollingWindow) add(now time.Time, failure bool) {
+	start := now.Truncate(w.width)
+	b := &w.buckets[int(start.UnixNano()/int64(w.width))%len(w.buckets)]
+	if !b.start.Equal(start) {
+		*b = bucket{start: start}
+	}
+	b.requests++
+	if failure {
+		b.failures++
+	}
+}
+
+func (w *rollingWindow) totals(now time.Time) (requests, failures int) {
+	oldest := now.Truncate(w.width).Add(-w.width * time.Duration(len(w.buckets)-1))
+	for _, b := range w.buckets {
+		if !b.start.Before(oldest) {
+			requests += b.requests
+			failures += b.failures
+		}
+	}
+	return
+}
+
+func (w *rollingWindow) reset() {
+	clear(w.buckets)
+}
+
+// CircuitBreaker stops calling a failing downstream for a while, it is routine-safe
+type CircuitBreaker struct {
+	mu          sync.Mutex
+	opts        BreakerOptions
+	state       BreakerState
+	generation  uint64 // increased on each state change, the results of the older generations are dropped
+	window      *rollingWindow
+	consecutive int
+	openedAt    time.Time
+	probes      int // in-flight probes in half-open
+	successes   int // succeeded probes in half-open
+}
+
+type stateChange struct {
+	from, to BreakerState
+}
+
+// NewCircuitBreaker returns a closed circuit breaker
+func NewCircuitBreaker(opts BreakerOptions) *CircuitBreaker {
+	opts.setDefaults()
+	return &CircuitBreaker{
+		opts:   opts,
+		window: newRollingWindow(opts.Window, opts.Buckets),
+	}
+}
+
+// Name returns the name in the options
+func (b *CircuitBreaker) Name() string {
+	return b.opts.Name
+}
+
+func (b *CircuitBreaker) notify(changes []stateChange) {
+	if b.opts.OnStateChange == nil {
+		return
+	}
+	for _, change := range changes {
+		b.opts.OnStateChange(b.opts.Name, change.from, change.to)
+	}
+}
+
+// setStateLocked changes the state and resets the counts, the lock must be held
+func (b *CircuitBreaker) setStateLocked(to BreakerState, now time.Time, changes []stateChange) []stateChange {
+	if b.state == to {
+		return changes
+	}
+	changes = append(changes, stateChange{from: b.state, to: to})
+	b.state = to
+	b.generation++
+	b.consecutive, b.probes, b.successes = 0, 0, 0
+	b.window.reset()
+	if to == StateOpen {
+		b.openedAt = now
+	}
+	return changes
+}
+
+// refreshLocked turns open to half-open when the timeout elapsed
+func (b *CircuitBreaker) refreshLocked(now time.Time, changes []stateChange) []stateChange {
+	if b.state == StateOpen && now.Sub(b.openedAt) >= b.opts.OpenTimeout {
+		return b.setStateLocked(StateHalfOpen, now, changes)
+	}
+	return changes
+}
+
+// State returns the current state
+func (b *CircuitBreaker) State() BreakerState {
+	b.mu.Lock()
+	changes := b.refreshLocked(b.opts.Now(), nil)
+	state := b.state
+	b.mu.Unlock()
+	b.notify(changes)
+	return state
+}
+
+// Counts returns the statistics of the current state
+func (b *CircuitBreaker) Counts() BreakerCounts {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	requests, failures := b.window.totals(b.opts.Now())
+	return BreakerCounts{Requests: requests, Failures: failures, ConsecutiveFailures: b.consecutive}
+}
+
+// Reset closes the breaker and clears the counts
+func (b *CircuitBreaker) Reset() {
+	b.mu.Lock()
+	changes := b.setStateLocked(StateClosed, b.opts.Now(), nil)
+	b.window.reset()
+	b.consecutive = 0
+	b.mu.Unlock()
+	b.notify(changes)
+}
+
+// Allow checks whether a call can be made, done must be called with the result of the call.
+// ErrCircuitOpen is returned when the breaker is open or the half-open probes are used up.
+func (b *CircuitBreaker) Allow() (done func(err error), err error) {
+	generation, err := b.allow()
+	if err != nil {
+		return nil, err
+	}
+	var once sync.Once
+	return func(err error) {
+		once.Do(func() { b.done(generation, b.opts.IsFailure(err)) })
+	}, nil
+}
+
+func (b *CircuitBreaker) allow() (generation uint64, err error) {
+	b.mu.Lock()
+	changes := b.refreshLocked(b.opts.Now(), nil)
+	switch {
+	case b.state == StateOpen:
+		err = ErrCircuitOpen
+	case b.state == StateHalfOpen && b.probes >= b.opts.HalfOpenRequests:
+		err = ErrCircuitOpen
+	case b.state == StateHalfOpen:
+		b.probes++
+	}
+	generation = b.generation
+	b.mu.Unlock()
+	b.notify(changes)
+	return
+}
+
+func (b *CircuitBreaker) done(generation uint64, failure bool) {
+	b.mu.Lock()
+	var changes []stateChange
+	now := b.opts.Now()
+	if generation == b.generation {
+		switch b.state {
+		case StateClosed:
+			b.window.add(now, failure)
+			if failure {
+				b.consecutive++
+			} else {
+				b.consecutive = 0
+			}
+			if b.shouldOpenLocked(now) {
+				changes = b.setStateLocked(StateOpen, now, changes)
+			}
+		case StateHalfOpen:
+			b.probes--
+			if failure {
+				changes = b.setStateLocked(StateOpen, now, changes)
+			} else if b.successes++; b.successes >= b.opts.HalfOpenRequests {
+				changes = b.setStateLocked(StateClosed, now, changes)
+			}
+		}
+	}
+	b.mu.Unlock()
+	b.notify(changes)
+}
+
+func (b *CircuitBreaker) shouldOpenLocked(now time.Time) bool {
+	if b.opts.ConsecutiveFailures > 0 && b.consecutive >= b.opts.ConsecutiveFailures {
+		return true
+	}
+	if b.opts.FailureRate > 0 {
+		requests, failures := b.window.totals(now)
+		return requests >= b.opts.MinRequests && float64(failures)/float64(requests) >= b.opts.FailureRate
+	}
+	return false
+}
+
+// Execute calls fn when the breaker allows and records the result, a panic of fn is
+// recorded as a failure and then re-panicked
+func (b *CircuitBreaker) Execute(ctx context.Context, fn func(context.Context) error) (err error) {
+	generation, err := b.allow()
+	if err != nil {
+		return err
+	}
+	completed := false
+	defer func() {
+		if !completed {
+			b.done(generation, true)
+		}
+	}()
+	err = fn(ctx)
+	completed = true
+	b.done(generation, b.opts.IsFailure(err))
+	return err
+}
@@ -0,0 +1,167 @@
+package hooks
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+type manualClock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+func newManualClock() *manualClock {
+	return &manualClock{now: time.Unix(1700000000, 0)}
+}
+
+func (c *manualClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *manualClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+}
+
+func failing(ctx context.Context) error { return errTemporary }
+
+func succeeding(ctx context.Context) error { return nil }
+
+func TestCircuitBreakerConsecutiveFailures(t *testing.T) {
+	clock := newManualClock()
+	var transitions []string
+	b := NewCircuitBreaker(BreakerOptions{
+		Name:                "downstream",
+		ConsecutiveFailures: 3,
+		OpenTimeout:         10 * time.Second,
+		HalfOpenRequests:    2,
+		Now:                 clock.Now,
+		OnStateChange: func(name string, from, to BreakerState) {
+			transitions = append(transitions, name+":"+from.String()+"->"+to.String())
+		},
+	})
+	ctx := context.Background()
+	b.Execute(ctx, failing)
+	b.Execute(ctx, failing)
+	b.Execute(ctx, succeeding)
+	b.Execute(ctx, failing)
+	b.Execute(ctx, failing)
+	if b.State() != StateClosed {
+		t.Fatal("success should reset the consecutive failures")
+	}
+	b.Execute(ctx, failing)
+	if b.State() != StateOpen {
+		t.Fatal("breaker should be open")
+	}
+	if err := b.Execute(ctx, succeeding); !errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("open breaker should reject, got %v", err)
+	}
+
+	clock.Advance(10 * time.Second)
+	done1, err1 := b.Allow()
+	done2, err2 := b.Allow()
+	if err1 != nil || err2 != nil || b.State() != StateHalfOpen {
+		t.Fatal("half-open should allow the probes")
+	}
+	if _, err := b.Allow(); !errors.Is(err, ErrCircuitOpen) {
+		t.Fatal("probes over the limit should be rejected")
+	}
+	done1(nil)
+	done2(nil)
+	if b.State() != StateClosed {
+		t.Fatal("succeeded probes should close the breaker")
+	}
+
+	want := []string{"downstream:closed->open", "downstream:open->half-open", "downstream:half-open->closed"}
+	if len(transitions) != len(want) {
+		t.Fatalf("unexpected transitions %v", transitions)
+	}
+	for i := range want {
+		if transitions[i] != want[i] {
+			t.Fatalf("unexpected transitions %v", transitions)
+		}
+	}
+}
+
+func TestCircuitBreakerHalfOpenFailure(t *testing.T) {
+	clock := newManualClock()
+	b := NewCircuitBreaker(BreakerOptions{ConsecutiveFailures: 1, OpenTimeout: time.Second, Now: clock.Now})
+	b.Execute(context.Background(), failing)
+	clock.Advance(time.Second)
+	b.Execute(context.Background(), failing)
+	if b.State() != StateOpen {
+		t.Fatal("failed probe should open the breaker again")
+	}
+	b.Reset()
+	if b.State() != StateClosed {
+		t.Fatal("reset should close the breaker")
+	}
+}
+
+func TestCircuitBreakerExecutePanic(t *testing.T) {
+	clock := newManualClock()
+	b := NewCircuitBreaker(BreakerOptions{ConsecutiveFailures: 1, OpenTimeout: time.Second, Now: clock.Now})
+	b.Execute(context.Background(), failing)
+	clock.Advance(time.Second)
+	func() {
+		defer func() {
+			if recover() == nil {
+				t.Fatal("the panic should be re-panicked")
+			}
+		}()
+		b.Execute(context.Background(), func(context.Context) error { panic("boom") })
+	}()
+	if b.State() != StateOpen {
+		t.Fatalf("panicked probe should open the breaker, got %v", b.State())
+	}
+	clock.Advance(time.Second)
+	if err := b.Execute(context.Background(), func(context.Context) error { return nil }); err != nil {
+		t.Fatalf("probe should be allowed after the open timeout, got %v", err)
+	}
+}
+
+func TestCircuitBreakerFailureRate(t *testing.T) {
+	clock := newManualClock()
+	b := NewCircuitBreaker(BreakerOptions{
+		ConsecutiveFailures: -1,
+		FailureRate:         0.5,
+		MinRequests:         4,
+		Window:              10 * time.Second,
+		Buckets:             10,
+		Now:                 clock.Now,
+	})
+	ctx := context.Background()
+	b.Execute(ctx, failing)
+	b.Execute(ctx, failing)
+	b.Execute(ctx, failing)
+	if b.State() != StateClosed {
+		t.Fatal("min requests not reached")
+	}
+	// the failures slide out of the window
+	clock.Advance(11 * time.Second)
+	b.Execute(ctx, succeeding)
+	b.Execute(ctx, succeeding)
+	b.Execute(ctx, failing)
+	if counts := b.Counts(); counts.Requests != 3 || counts.Failures != 1 || b.State() != StateClosed {
+		t.Fatalf("unexpected counts %+v", counts)
+	}
+	clock.Advance(time.Second)
+	b.Execute(ctx, failing)
+	if b.State() != StateOpen {
+		t.Fatal("failure rate 50% should open the breaker")
+	}
+}
+
+func TestCircuitBreakerIsFailure(t *testing.T) {
+	b := NewCircuitBreaker(BreakerOptions{ConsecutiveFailures: 1})
+	b.Execute(context.Background(), func(ctx context.Context) error { return context.Canceled })
+	if b.State() != StateClosed {
+		t.Fatal("canceled call should not be a failure")
+	}
+}
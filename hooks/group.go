@@ -0,0 +1,108 @@
+package hooks
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// Group holds an instance per key, such as a circuit breaker per target host, it is routine-safe
+type Group[T any] struct {
+	mu          sync.Mutex
+	newFn       func(key string) T
+	items       map[string]T
+	lastUsed    map[string]time.Time
+	idleTimeout time.Duration
+	lastSweep   time.Time
+	now         func() time.Time
+}
+
+// NewGroup returns a group creating the instances by newFn on first use
+func NewGroup[T any](newFn func(key string) T) *Group[T] {
+	return &Group[T]{
+		newFn:    newFn,
+		items:    make(map[string]T),
+		lastUsed: make(map[string]time.Time),
+		now:      time.Now,
+	}
+}
+
+// SetIdleTimeout evicts the instances unused for the timeout, 0 means never evicting.
+// The idle instances are swept by Get at most once per timeout.
+func (g *Group[T]) SetIdleTimeout(timeout time.Duration) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.idleTimeout = timeout
+}
+
+// EvictIdle removes the instances unused for the idle time and returns the number removed
+func (g *Group[T]) EvictIdle(idle time.Duration) int {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return g.evictIdleLocked(g.now(), idle)
+}
+
+func (g *Group[T]) evictIdleLocked(now time.Time, idle time.Duration) (evicted int) {
+	for key, used := range g.lastUsed {
+		if now.Sub(used) >= idle {
+			delete(g.items, key)
+			delete(g.lastUsed, key)
+			evicted++
+		}
+	}
+	g.lastSweep = now
+	return
+}
+
+// Get returns the instance of the key, which is created when absent
+func (g *Group[T]) Get(key string) T {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	now := g.now()
+	if g.idleTimeout > 0 && now.Sub(g.lastSweep) >= g.idleTimeout {
+		g.evictIdleLocked(now, g.idleTimeout)
+	}
+	item, exists := g.items[key]
+	if !exists {
+		item = g.newFn(key)
+		g.items[key] = item
+	}
+	g.lastUsed[key] = now
+	return item
+}
+
+// Delete removes the instance of the key
+func (g *Group[T]) Delete(key string) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	delete(g.items, key)
+	delete(g.lastUsed, key)
+}
+
+// Keys returns the sorted keys
+func (g *Group[T]) Keys() []string {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	keys := make([]string, 0, len(g.items))
+	for key := range g.items {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// NewBreakerGroup returns a group of circuit breakers named by their keys
+func NewBreakerGroup(opts BreakerOptions) *Group[*CircuitBreaker] {
+	return NewGroup(func(key string) *CircuitBreaker {
+		breakerOpts := opts
+		breakerOpts.Name = key
+		return NewCircuitBreaker(breakerOpts)
+	})
+}
+
+// NewBulkheadGroup returns a group of bulkheads with the same limits
+func NewBulkheadGroup(maxConcurrent, maxQueue int, queueTimeout time.Duration) *Group[*Bulkhead] {
+	return NewGroup(func(string) *Bulkhead {
+		return NewBulkhead(maxConcurrent, maxQueue, queueTimeout)
+	})
+}
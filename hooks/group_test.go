@@ -0,0 +1,22 @@
+package hooks
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestGroup(t *testing.T) {
+	g := NewBreakerGroup(BreakerOptions{})
+	a := g.Get("a.example.com")
+	if g.Get("a.example.com") != a || a.Name() != "a.example.com" {
+		t.Fatal("same key should return the same breaker")
+	}
+	g.Get("b.example.com")
+	if !slices.Equal(g.Keys(), []string{"a.example.com", "b.example.com"}) {
+		t.Fatalf("unexpected keys %v", g.Keys())
+	}
+	g.Delete("a.example.com")
+	if g.Get("a.example.com") == a {
+		t.Fatal("deleted key should be recreated")
+	}
+}
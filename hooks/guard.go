@@ -0,0 +1,60 @@
+package hooks
+
+import (
+	"context"
+	"errors"
+)
+
+// Guard composes the resilience primitives around a task, the nil ones are skipped.
+//
+// Each attempt of Retry waits for the Limiter, takes a slot of the Bulkhead and then passes
+// the Breaker, so the rejections of the bulkhead are not counted as failures by the breaker.
+// The retry stops when the breaker is open, as retrying an open circuit only wastes the attempts.
+type Guard struct {
+	Retry    *RetryPolicy
+	Breaker  *CircuitBreaker
+	Bulkhead *Bulkhead
+	Limiter  Limiter // observes the results when it is an Observer
+}
+
+// Run runs fn guarded by the primitives
+func (g *Guard) Run(ctx context.Context, taskName string, fn func(context.Context) error) error {
+	call := fn
+	if g.Breaker != nil {
+		call = func(ctx context.Context) error {
+			err := g.Breaker.Execute(ctx, fn)
+			if errors.Is(err, ErrCircuitOpen) {
+				return Permanent(err)
+			}
+			return err
+		}
+	}
+	attempt := call
+	if g.Bulkhead != nil {
+		attempt = func(ctx context.Context) error {
+			return g.Bulkhead.Execute(ctx, call)
+		}
+	}
+	if g.Limiter != nil {
+		limited := attempt
+		attempt = func(ctx context.Context) error {
+			if err := g.Limiter.Wait(ctx); err != nil {
+				return err
+			}
+			err := limited(ctx)
+			if observer, ok := g.Limiter.(Observer); ok {
+				observer.Observe(err)
+			}
+			return err
+		}
+	}
+	if g.Retry == nil {
+		err := attempt(ctx)
+		var permanent *PermanentError
+		if errors.As(err, &permanent) {
+			return permanent.Err
+		}
+		return err
+	}
+	return g.Retry.Run(ctx, taskName, attempt)
+}
@@ -0,0 +1,61 @@
+package hooks
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// Hedge runs fn and launches a speculative call when it does not finish within the delay,
+// the first success is returned and the other call is canceled. The errors are joined when
+// both calls fail, and the error is returned at once when fn fails before the delay,
+// delay <= 0 disables hedging.
+//
+// fn should be idempotent since it may run twice.
+func Hedge[T any](ctx context.Context, delay time.Duration, fn func(context.Context) (T, error)) (T, error) {
+	if delay <= 0 {
+		return fn(ctx)
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	type result struct {
+		value T
+		err   error
+	}
+	results := make(chan result, 2)
+	launch := func() {
+		go func() {
+			value, err := fn(ctx)
+			results <- result{value, err}
+		}()
+	}
+	launch()
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	var (
+		last    result
+		errs    []error
+		running = 1
+		hedged  bool
+	)
+	for running > 0 {
+		select {
+		case <-timer.C:
+			hedged = true
+			running++
+			launch()
+		case last = <-results:
+			running--
+			if last.err == nil {
+				return last.value, nil
+			}
+			errs = append(errs, last.err)
+			if !hedged {
+				return last.value, last.err
+			}
+		}
+	}
+	return last.value, errors.Join(errs...)
+}
@@ -0,0 +1,61 @@
+package hooks
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestHedge(t *testing.T) {
+	var calls, canceled atomic.Int32
+	value, err := Hedge(context.Background(), 5*time.Millisecond, func(ctx context.Context) (int, error) {
+		n := calls.Add(1)
+		if n == 1 {
+			<-ctx.Done()
+			canceled.Add(1)
+			return 0, ctx.Err()
+		}
+		return int(n), nil
+	})
+	if err != nil || value != 2 {
+		t.Fatalf("speculative call should win, got %d, %v", value, err)
+	}
+	time.Sleep(10 * time.Millisecond)
+	if canceled.Load() != 1 {
+		t.Fatal("slow call should be canceled")
+	}
+
+	calls.Store(0)
+	value, err = Hedge(context.Background(), 50*time.Millisecond, func(ctx context.Context) (int, error) {
+		return int(calls.Add(1)), nil
+	})
+	if err != nil || value != 1 || calls.Load() != 1 {
+		t.Fatal("fast call should not be hedged")
+	}
+}
+
+func TestHedgeErrors(t *testing.T) {
+	errFirst, errSecond := errors.New("first"), errors.New("second")
+	var calls atomic.Int32
+	_, err := Hedge(context.Background(), time.Millisecond, func(ctx context.Context) (int, error) {
+		if calls.Add(1) == 1 {
+			time.Sleep(10 * time.Millisecond)
+			return 0, errFirst
+		}
+		return 0, errSecond
+	})
+	if !errors.Is(err, errFirst) || !errors.Is(err, errSecond) {
+		t.Fatalf("errors of both calls should be joined, got %v", err)
+	}
+
+	calls.Store(0)
+	_, err = Hedge(context.Background(), 10*time.Millisecond, func(ctx context.Context) (int, error) {
+		calls.Add(1)
+		return 0, errFirst
+	})
+	if err != errFirst || calls.Load() != 1 {
+		t.Fatalf("early failure should be returned at once, got %v", err)
+	}
+}
@@ -0,0 +1,353 @@
+package hooks
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/duoland/base/container/queue"
+	"github.com/duoland/base/logit"
+	nethttp "github.com/duoland/base/net/http"
+)
+
+var (
+	ErrDuplicateHook     = errors.New("hooks: duplicate hook")
+	ErrUnknownDependency = errors.New("hooks: unknown hook dependency")
+	ErrDependencyCycle   = errors.New("hooks: hook dependency cycle")
+	ErrLifecycleStarted  = errors.New("hooks: lifecycle already started")
+)
+
+// Hook is the start and stop hooks of a component, both OnStart and OnStop are optional
+type Hook struct {
+	Name      string
+	DependsOn []string      // hooks started before and stopped after this one
+	Priority  int           // the ready hooks with lower priority start first
+	Timeout   time.Duration // timeout of each of OnStart and OnStop, 0 means LifecycleOptions.HookTimeout
+	OnStart   func(ctx context.Context) error
+	OnStop    func(ctx context.Context) error // also called when OnStart timed out, since it may have started partly
+}
+
+// LifecycleOptions configures a Lifecycle, the zero timeouts mean no limit
+type LifecycleOptions struct {
+	StartTimeout time.Duration // timeout of starting all the hooks
+	StopTimeout  time.Duration // timeout of stopping all the hooks
+	HookTimeout  time.Duration // default timeout of each hook
+	Signals      []os.Signal   // signals to stop Run, default SIGINT and SIGTERM
+	Logger       Logger        // default NopLogger
+}
+
+// Lifecycle starts the hooks in the order of their dependencies and priorities,
+// and stops the started ones in reverse, it is routine-safe. It can be started again
+// after Stop, or after Start fails.
+type Lifecycle struct {
+	opts LifecycleOptions
+
+	mu       sync.Mutex
+	hooks    []Hook
+	names    map[string]int
+	started  []Hook
+	running  bool
+	shutdown chan struct{}
+	cause    error
+}
+
+// NewLifecycle returns an empty lifecycle
+func NewLifecycle(opts LifecycleOptions) *Lifecycle {
+	if len(opts.Signals) == 0 {
+		opts.Signals = []os.Signal{os.Interrupt, syscall.SIGTERM}
+	}
+	if opts.Logger == nil {
+		opts.Logger = NopLogger
+	}
+	return &Lifecycle{
+		opts:     opts,
+		names:    make(map[string]int),
+		shutdown: make(chan struct{}),
+	}
+}
+
+// Append registers the hook, an unnamed hook gets the name "hook-N" by its registration order.
+// The dependencies are checked by Start, so they can be appended later.
+func (l *Lifecycle) Append(hook Hook) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.running {
+		return ErrLifecycleStarted
+	}
+	if hook.Name == "" {
+		hook.Name = fmt.Sprintf("hook-%d", len(l.hooks)+1)
+	}
+	if _, exists := l.names[hook.Name]; exists {
+		return fmt.Errorf("%w: %s", ErrDuplicateHook, hook.Name)
+	}
+	l.names[hook.Name] = len(l.hooks)
+	l.hooks = append(l.hooks, hook)
+	return nil
+}
+
+// order sorts the hooks topologically, the ready hooks are taken by priority and then registration order
+func (l *Lifecycle) order() ([]Hook, error) {
+	indegrees := make([]int, len(l.hooks))
+	dependents := make([][]int, len(l.hooks))
+	for i, hook := range l.hooks {
+		for _, dep := range hook.DependsOn {
+			j, exists := l.names[dep]
+			if !exists {
+				return nil, fmt.Errorf("%w: %s depends on %s", ErrUnknownDependency, hook.Name, dep)
+			}
+			indegrees[i]++
+			dependents[j] = append(dependents[j], i)
+		}
+	}
+
+	ready := queue.NewPriorityQueue(func(a, b int) bool {
+		if l.hooks[a].Priority != l.hooks[b].Priority {
+			return l.hooks[a].Priority < l.hooks[b].Priority
+		}
+		return a < b
+	})
+	for i, indegree := range indegrees {
+		if indegree == 0 {
+			ready.Push(i)
+		}
+	}
+	ordered := make([]Hook, 0, len(l.hooks))
+	for i := range ready.Drain() {
+		ordered = append(ordered, l.hooks[i])
+		for _, j := range dependents[i] {
+			if indegrees[j]--; indegrees[j] == 0 {
+				ready.Push(j)
+			}
+		}
+	}
+	if len(ordered) < len(l.hooks) {
+		for i, indegree := range indegrees {
+			if indegree > 0 {
+				return nil, fmt.Errorf("%w: %s", ErrDependencyCycle, l.hooks[i].Name)
+			}
+		}
+	}
+	return ordered, nil
+}
+
+// Start runs OnStart of the hooks one by one, when a hook fails, the started hooks are
+// stopped in reverse and the error is returned.
+func (l *Lifecycle) Start(ctx context.Context) error {
+	l.mu.Lock()
+	if l.running {
+		l.mu.Unlock()
+		return ErrLifecycleStarted
+	}
+	ordered, err := l.order()
+	if err != nil {
+		l.mu.Unlock()
+		return err
+	}
+	l.running = true
+	l.mu.Unlock()
+
+	ctx, cancel := withOptionalTimeout(ctx, l.opts.StartTimeout)
+	defer cancel()
+	for _, hook := range ordered {
+		l.opts.Logger.Printf("start hook %s ...", hook.Name)
+		err, abandoned := l.runHook(ctx, hook, hook.OnStart)
+		if err == nil || abandoned {
+			l.mu.Lock()
+			l.started = append(l.started, hook)
+			l.mu.Unlock()
+		}
+		if err != nil {
+			err = fmt.Errorf("start %s: %w", hook.Name, err)
+			return errors.Join(err, l.Stop(context.WithoutCancel(ctx)))
+		}
+	}
+	return nil
+}
+
+// Stop runs OnStop of the started hooks in reverse order, a failed hook does not stop the others,
+// and the errors are joined.
+func (l *Lifecycle) Stop(ctx context.Context) error {
+	l.mu.Lock()
+	started := l.started
+	l.started = nil
+	l.running = false
+	l.mu.Unlock()
+
+	ctx, cancel := withOptionalTimeout(ctx, l.opts.StopTimeout)
+	defer cancel()
+	var errs []error
+	for i := len(started) - 1; i >= 0; i-- {
+		hook := started[i]
+		l.opts.Logger.Printf("stop hook %s ...", hook.Name)
+		if err, _ := l.runHook(ctx, hook, hook.OnStop); err != nil {
+			errs = append(errs, fmt.Errorf("stop %s: %w", hook.Name, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// runHook runs fn with the hook timeout, it returns when the timeout is reached even if fn
+// ignores the context, so a stuck hook does not block the others. abandoned is true when
+// fn was still running at the timeout.
+func (l *Lifecycle) runHook(ctx context.Context, hook Hook, fn func(ctx context.Context) error) (err error, abandoned bool) {
+	if fn == nil {
+		return nil, false
+	}
+	if err := ctx.Err(); err != nil {
+		return err, false
+	}
+	timeout := hook.Timeout
+	if timeout <= 0 {
+		timeout = l.opts.HookTimeout
+	}
+	ctx, cancel := withOptionalTimeout(ctx, timeout)
+	defer cancel()
+	done := make(chan error, 1)
+	go func() {
+		defer func() {
+			if r := recover(); r != nil {
+				done <- fmt.Errorf("hook panic: %v", r)
+			}
+		}()
+		done <- fn(ctx)
+	}()
+	select {
+	case err := <-done:
+		return err, false
+	case <-ctx.Done():
+		return ctx.Err(), true
+	}
+}
+
+// Shutdown asks Run to stop the hooks, a non-nil cause is returned by Run, only the first call
+// takes effect until Run returns
+func (l *Lifecycle) Shutdown(cause error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	select {
+	case <-l.shutdown:
+	default:
+		l.cause = cause
+		close(l.shutdown)
+	}
+}
+
+// Run starts the hooks, waits for the signals, ctx done or Shutdown, and then stops the hooks,
+// so a service boots and drains with one call.
+func (l *Lifecycle) Run(ctx context.Context) error {
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, l.opts.Signals...)
+	defer signal.Stop(signals)
+	l.mu.Lock()
+	shutdown := l.shutdown
+	l.mu.Unlock()
+	defer l.resetShutdown()
+	if err := l.Start(ctx); err != nil {
+		return err
+	}
+
+	select {
+	case sig := <-signals:
+		l.opts.Logger.Printf("lifecycle got signal %s, stopping ...", sig)
+	case <-ctx.Done():
+		l.opts.Logger.Printf("lifecycle canceled, stopping ...")
+	case <-shutdown:
+		l.opts.Logger.Printf("lifecycle shutdown, stopping ...")
+	}
+
+	err := l.Stop(context.WithoutCancel(ctx))
+	l.mu.Lock()
+	cause := l.cause
+	l.mu.Unlock()
+	return errors.Join(cause, err)
+}
+
+// resetShutdown makes Shutdown take effect again for the next Run
+func (l *Lifecycle) resetShutdown() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	select {
+	case <-l.shutdown:
+		l.cause = nil
+		l.shutdown = make(chan struct{})
+	default:
+	}
+}
+
+// AppendServer registers the server, which serves in background when started, and exits
+// gracefully by closing stopSignal when stopped, stopSignal must be the one of the server.
+// The connections are closed when OnStop times out, and Run stops when the server fails.
+// The server can not be started again after stopped.
+func (l *Lifecycle) AppendServer(name string, srv *nethttp.GraceExitServer, stopSignal chan<- struct{}, dependsOn ...string) error {
+	served := make(chan struct{})
+	var stopOnce sync.Once
+	stopping := make(chan struct{})
+	return l.Append(Hook{
+		Name:      name,
+		DependsOn: dependsOn,
+		OnStart: func(ctx context.Context) error {
+			select {
+			case <-stopping:
+				return fmt.Errorf("server %s is stopped", name)
+			default:
+			}
+			go func() {
+				defer close(served)
+				err := srv.ListenAndServe()
+				select {
+				case <-stopping:
+				default:
+					if err != nil {
+						l.Shutdown(fmt.Errorf("serve %s: %w", name, err))
+					}
+				}
+			}()
+			return nil
+		},
+		OnStop: func(ctx context.Context) error {
+			stopOnce.Do(func() {
+				close(stopping)
+				close(stopSignal)
+			})
+			select {
+			case <-served:
+				return nil
+			case <-ctx.Done():
+				srv.Close()
+				return ctx.Err()
+			}
+		},
+	})
+}
+
+// LogitHook flushes the logit logs when stopped, it has the lowest priority to start first and stop last
+func LogitHook() Hook {
+	return Hook{
+		Name:     "logit",
+		Priority: math.MinInt,
+		OnStop: func(ctx context.Context) error {
+			if logit.Logger == nil {
+				return nil
+			}
+			err := logit.Logger.Sync()
+			// syncing the console is not supported on some platforms
+			if errors.Is(err, syscall.EINVAL) || errors.Is(err, syscall.ENOTTY) {
+				return nil
+			}
+			return err
+		},
+	}
+}
+
+func withOptionalTimeout(ctx context.Context, timeout time.Duration) (context.Context, context.CancelFunc) {
+	if timeout <= 0 {
+		return context.WithCancel(ctx)
+	}
+	return context.WithTimeout(ctx, timeout)
+}
@@ -0,0 +1,256 @@
+package hooks
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"slices"
+	"sync"
+	"syscall"
+	"testing"
+	"time"
+
+	nethttp "github.com/duoland/base/net/http"
+)
+
+type hookRecorder struct {
+	mu     sync.Mutex
+	events []string
+}
+
+func (r *hookRecorder) hook(name string, priority int, dependsOn ...string) Hook {
+	record := func(event string) func(context.Context) error {
+		return func(context.Context) error {
+			r.mu.Lock()
+			defer r.mu.Unlock()
+			r.events = append(r.events, event+" "+name)
+			return nil
+		}
+	}
+	return Hook{Name: name, Priority: priority, DependsOn: dependsOn, OnStart: record("start"), OnStop: record("stop")}
+}
+
+func TestLifecycleOrder(t *testing.T) {
+	r := &hookRecorder{}
+	l := NewLifecycle(LifecycleOptions{})
+	l.Append(r.hook("server", 0, "db", "cache"))
+	l.Append(r.hook("cache", 2))
+	l.Append(r.hook("db", 1))
+	l.Append(r.hook("metrics", 5))
+	if err := l.Append(r.hook("db", 0)); !errors.Is(err, ErrDuplicateHook) {
+		t.Fatalf("duplicate hook should be rejected, got %v", err)
+	}
+	if err := l.Start(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if err := l.Start(context.Background()); !errors.Is(err, ErrLifecycleStarted) {
+		t.Fatalf("second start should be rejected, got %v", err)
+	}
+	if err := l.Stop(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"start db", "start cache", "start server", "start metrics", "stop metrics", "stop server", "stop cache", "stop db"}
+	if !slices.Equal(r.events, want) {
+		t.Fatalf("unexpected events %v", r.events)
+	}
+}
+
+func TestLifecycleInvalidDependencies(t *testing.T) {
+	l := NewLifecycle(LifecycleOptions{})
+	l.Append(Hook{Name: "a", DependsOn: []string{"missing"}})
+	if err := l.Start(context.Background()); !errors.Is(err, ErrUnknownDependency) {
+		t.Fatalf("unknown dependency should be rejected, got %v", err)
+	}
+
+	l = NewLifecycle(LifecycleOptions{})
+	l.Append(Hook{Name: "a", DependsOn: []string{"b"}})
+	l.Append(Hook{Name: "b", DependsOn: []string{"a"}})
+	if err := l.Start(context.Background()); !errors.Is(err, ErrDependencyCycle) {
+		t.Fatalf("cycle should be rejected, got %v", err)
+	}
+}
+
+func TestLifecycleStartFailure(t *testing.T) {
+	r := &hookRecorder{}
+	errBoom := errors.New("boom")
+	l := NewLifecycle(LifecycleOptions{})
+	l.Append(r.hook("db", 0))
+	l.Append(Hook{Name: "broken", Priority: 1, OnStart: func(context.Context) error { return errBoom }})
+	l.Append(r.hook("server", 2))
+	err := l.Start(context.Background())
+	if !errors.Is(err, errBoom) {
+		t.Fatalf("start error should be returned, got %v", err)
+	}
+	if !slices.Equal(r.events, []string{"start db", "stop db"}) {
+		t.Fatalf("started hooks should be stopped, got %v", r.events)
+	}
+}
+
+func TestLifecycleTimeouts(t *testing.T) {
+	r := &hookRecorder{}
+	l := NewLifecycle(LifecycleOptions{HookTimeout: 10 * time.Millisecond})
+	l.Append(r.hook("db", 0))
+	l.Append(Hook{Name: "stuck", Priority: 1, OnStop: func(context.Context) error {
+		time.Sleep(time.Second)
+		return nil
+	}})
+	l.Append(Hook{Name: "slow", Priority: 2, Timeout: 50 * time.Millisecond, OnStop: func(ctx context.Context) error {
+		select {
+		case <-time.After(20 * time.Millisecond):
+			return nil
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}})
+	if err := l.Start(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	start := time.Now()
+	err := l.Stop(context.Background())
+	if !errors.Is(err, context.DeadlineExceeded) || !slices.Equal(r.events, []string{"start db", "stop db"}) {
+		t.Fatalf("stuck hook should time out and the others should stop, got %v, %v", err, r.events)
+	}
+	if time.Since(start) > 500*time.Millisecond {
+		t.Fatal("stop should not wait for the stuck hook")
+	}
+}
+
+func TestLifecycleStartTimeout(t *testing.T) {
+	r := &hookRecorder{}
+	stopped := make(chan struct{})
+	l := NewLifecycle(LifecycleOptions{HookTimeout: 10 * time.Millisecond})
+	l.Append(r.hook("db", 0))
+	l.Append(Hook{
+		Name:     "stuck",
+		Priority: 1,
+		OnStart: func(context.Context) error {
+			time.Sleep(time.Second)
+			return nil
+		},
+		OnStop: func(context.Context) error {
+			close(stopped)
+			return nil
+		},
+	})
+	if err := l.Start(context.Background()); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("start should time out, got %v", err)
+	}
+	select {
+	case <-stopped:
+	default:
+		t.Fatal("timed out hook should be stopped")
+	}
+	if !slices.Equal(r.events, []string{"start db", "stop db"}) {
+		t.Fatalf("unexpected events %v", r.events)
+	}
+}
+
+func TestLifecycleRestart(t *testing.T) {
+	r := &hookRecorder{}
+	fail := true
+	l := NewLifecycle(LifecycleOptions{})
+	l.Append(r.hook("db", 0))
+	l.Append(Hook{Name: "flaky", Priority: 1, OnStart: func(context.Context) error {
+		if fail {
+			return errors.New("boom")
+		}
+		return nil
+	}})
+	if err := l.Start(context.Background()); err == nil {
+		t.Fatal("first start should fail")
+	}
+	fail = false
+	if err := l.Start(context.Background()); err != nil {
+		t.Fatalf("start should be retried after a failure, got %v", err)
+	}
+	if err := l.Stop(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	errCause := errors.New("cause")
+	for i := 0; i < 2; i++ {
+		l.Shutdown(errCause)
+		if err := l.Run(context.Background()); !errors.Is(err, errCause) {
+			t.Fatalf("run %d should return the cause, got %v", i, err)
+		}
+	}
+	want := []string{"start db", "stop db", "start db", "stop db", "start db", "stop db", "start db", "stop db"}
+	if !slices.Equal(r.events, want) {
+		t.Fatalf("unexpected events %v", r.events)
+	}
+}
+
+func TestLifecycleRunSignal(t *testing.T) {
+	r := &hookRecorder{}
+	l := NewLifecycle(LifecycleOptions{Signals: []os.Signal{syscall.SIGUSR1}})
+	l.Append(r.hook("db", 0))
+	l.Append(LogitHook())
+	done := make(chan error, 1)
+	go func() { done <- l.Run(context.Background()) }()
+	time.Sleep(20 * time.Millisecond)
+	syscall.Kill(syscall.Getpid(), syscall.SIGUSR1)
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatal(err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("run should stop on the signal")
+	}
+	if !slices.Equal(r.events, []string{"start db", "stop db"}) {
+		t.Fatalf("unexpected events %v", r.events)
+	}
+}
+
+func TestLifecycleServer(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	port := ln.Addr().(*net.TCPAddr).Port
+	ln.Close()
+
+	stop := make(chan struct{})
+	srv := nethttp.NewGraceExitServerWithHandler("127.0.0.1", port, stop, http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		w.Write([]byte("ok"))
+	}))
+	l := NewLifecycle(LifecycleOptions{StopTimeout: 2 * time.Second})
+	if err := l.AppendServer("http", srv, stop); err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- l.Run(ctx) }()
+
+	var resp *http.Response
+	for i := 0; i < 50; i++ {
+		if resp, err = http.Get(fmt.Sprintf("http://127.0.0.1:%d/", port)); err == nil {
+			resp.Body.Close()
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+	cancel()
+	if err := <-done; err != nil {
+		t.Fatalf("server should exit gracefully, got %v", err)
+	}
+
+	// the port is taken, so the server fails and Run stops
+	busy, _ := net.Listen("tcp", fmt.Sprintf("127.0.0.1:%d", port))
+	if busy != nil {
+		defer busy.Close()
+	}
+	stop = make(chan struct{})
+	srv = nethttp.NewGraceExitServer("127.0.0.1", port, stop)
+	l = NewLifecycle(LifecycleOptions{})
+	l.AppendServer("http", srv, stop)
+	if err := l.Run(context.Background()); err == nil {
+		t.Fatal("server failure should be returned")
+	}
+}
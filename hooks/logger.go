@@ -0,0 +1,35 @@
+package hooks
+
+import (
+	"log"
+
+	"github.com/duoland/base/logit"
+)
+
+// Logger prints the progress of the hooks
+type Logger interface {
+	Printf(format string, args ...any)
+}
+
+// LoggerFunc adapts a printf-like func to Logger
+type LoggerFunc func(format string, args ...any)
+
+// Printf calls f
+func (f LoggerFunc) Printf(format string, args ...any) {
+	f(format, args...)
+}
+
+var (
+	// StdLogger prints with the default logger of the stdlib log package
+	StdLogger Logger = LoggerFunc(log.Printf)
+	// LogitLogger prints with logit.Infof, and with StdLogger before logit.InitLogs
+	LogitLogger Logger = LoggerFunc(func(format string, args ...any) {
+		if logit.Infof == nil {
+			StdLogger.Printf(format, args...)
+			return
+		}
+		logit.Infof(format, args...)
+	})
+	// NopLogger prints nothing
+	NopLogger Logger = LoggerFunc(func(string, ...any) {})
+)
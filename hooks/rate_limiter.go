@@ -0,0 +1,399 @@
+package hooks
+
+import (
+	"context"
+	"errors"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ErrRateLimited is returned by Wait when the call can not be made before the context deadline
+// or the queue of the leaky bucket is full
+var ErrRateLimited = errors.New("hooks: rate limited")
+
+// Limiter limits the rate of the calls
+type Limiter interface {
+	// Allow takes a permit without waiting, it returns false when no permit is available now
+	Allow() bool
+	// Wait blocks until a permit is available or ctx is done
+	Wait(ctx context.Context) error
+}
+
+// Observer is implemented by the limiters adapting to the results of the calls
+type Observer interface {
+	Observe(err error)
+}
+
+// sleepUntil waits until t or ctx is done
+func sleepUntil(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return ctx.Err()
+	}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// exceedsDeadline returns true when waiting for d passes the deadline of ctx
+func exceedsDeadline(ctx context.Context, d time.Duration) bool {
+	deadline, ok := ctx.Deadline()
+	return ok && time.Until(deadline) < d
+}
+
+// TokenBucket allows bursts up to the bucket size and refills at the rate, it is routine-safe
+type TokenBucket struct {
+	mu     sync.Mutex
+	rate   float64 // tokens per second
+	burst  float64
+	tokens float64
+	last   time.Time
+	now    func() time.Time
+}
+
+// NewTokenBucket returns a full token bucket, rate is the tokens per second and burst is the bucket size,
+// it panics when rate is not positive
+func NewTokenBucket(rate float64, burst int) *TokenBucket {
+	if !(rate > 0) {
+		panic("hooks: token bucket rate must be positive")
+	}
+	burst = max(burst, 1)
+	return &TokenBucket{
+		rate:   rate,
+		burst:  float64(burst),
+		tokens: float64(burst),
+		now:    time.Now,
+	}
+}
+
+// refillLocked adds the tokens since the last refill, the lock must be held
+func (b *TokenBucket) refillLocked(now time.Time) {
+	if !b.last.IsZero() && now.After(b.last) {
+		b.tokens = math.Min(b.burst, b.tokens+now.Sub(b.last).Seconds()*b.rate)
+	}
+	b.last = now
+}
+
+// Rate returns the tokens per second
+func (b *TokenBucket) Rate() float64 {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.rate
+}
+
+// SetRate changes the tokens per second, the tokens already in the bucket are kept
+func (b *TokenBucket) SetRate(rate float64) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.refillLocked(b.now())
+	b.rate = rate
+}
+
+// updateRate changes the tokens per second by fn of the current rate in one step
+func (b *TokenBucket) updateRate(fn func(rate float64) float64) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.refillLocked(b.now())
+	b.rate = fn(b.rate)
+}
+
+// Allow takes a token without waiting
+func (b *TokenBucket) Allow() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.refillLocked(b.now())
+	if b.tokens < 1 {
+		return false
+	}
+	b.tokens--
+	return true
+}
+
+// Wait takes a token, waiting for the refill when the bucket is empty.
+// ErrRateLimited is returned immediately when the token is not available before the deadline of ctx.
+func (b *TokenBucket) Wait(ctx context.Context) error {
+	b.mu.Lock()
+	now := b.now()
+	b.refillLocked(now)
+	b.tokens--
+	var wait time.Duration
+	if b.tokens < 0 {
+		if b.rate <= 0 {
+			b.tokens++
+			b.mu.Unlock()
+			return ErrRateLimited
+		}
+		wait = time.Duration(-b.tokens / b.rate * float64(time.Second))
+	}
+	if exceedsDeadline(ctx, wait) {
+		b.tokens++
+		b.mu.Unlock()
+		return ErrRateLimited
+	}
+	b.mu.Unlock()
+
+	if err := sleepUntil(ctx, wait); err != nil {
+		// give back the reserved token
+		b.mu.Lock()
+		b.tokens = math.Min(b.burst, b.tokens+1)
+		b.mu.Unlock()
+		return err
+	}
+	return nil
+}
+
+// LeakyBucket spaces the calls evenly at the rate without bursts, at most capacity calls
+// wait in the queue, it is routine-safe
+type LeakyBucket struct {
+	mu       sync.Mutex
+	interval time.Duration
+	capacity int
+	next     time.Time // the earliest time of the next call
+	now      func() time.Time
+}
+
+// NewLeakyBucket returns a leaky bucket letting rate calls per second with the queue capacity,
+// it panics when rate is not positive
+func NewLeakyBucket(rate float64, capacity int) *LeakyBucket {
+	if !(rate > 0) {
+		panic("hooks: leaky bucket rate must be positive")
+	}
+	return &LeakyBucket{
+		interval: time.Duration(float64(time.Second) / rate),
+		capacity: max(capacity, 0),
+		now:      time.Now,
+	}
+}
+
+// Allow lets the call go when it needs no waiting
+func (b *LeakyBucket) Allow() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	now := b.now()
+	if b.next.After(now) {
+		return false
+	}
+	b.next = now.Add(b.interval)
+	return true
+}
+
+// Wait waits for the turn of the call, ErrRateLimited is returned immediately when the queue
+// is full or the turn is after the deadline of ctx. The turn is given back when ctx is done
+// while waiting.
+func (b *LeakyBucket) Wait(ctx context.Context) error {
+	b.mu.Lock()
+	now := b.now()
+	turn := now
+	if b.next.After(now) {
+		turn = b.next
+	}
+	wait := turn.Sub(now)
+	if wait > time.Duration(b.capacity)*b.interval || exceedsDeadline(ctx, wait) {
+		b.mu.Unlock()
+		return ErrRateLimited
+	}
+	b.next = turn.Add(b.interval)
+	b.mu.Unlock()
+
+	if err := sleepUntil(ctx, wait); err != nil {
+		// give back the reserved turn
+		b.mu.Lock()
+		b.next = b.next.Add(-b.interval)
+		b.mu.Unlock()
+		return err
+	}
+	return nil
+}
+
+// ThrottledError means the server throttles the calls, such as HTTP 429 and 503
+type ThrottledError struct {
+	Err        error
+	RetryAfter time.Duration // 0 when the server does not tell
+}
+
+func (e *ThrottledError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *ThrottledError) Unwrap() error {
+	return e.Err
+}
+
+// Throttled implements the throttle check of IsThrottled
+func (e *ThrottledError) Throttled() (time.Duration, bool) {
+	return e.RetryAfter, true
+}
+
+// Throttled wraps the error as a throttled one, nil is returned for nil
+func Throttled(err error, retryAfter time.Duration) error {
+	if err == nil {
+		return nil
+	}
+	return &ThrottledError{Err: err, RetryAfter: retryAfter}
+}
+
+// IsThrottled returns true when the error or any error it wraps has a Throttled method
+// returning true, such as ThrottledError, retryAfter is the delay suggested by the server.
+func IsThrottled(err error) (retryAfter time.Duration, ok bool) {
+	var throttled interface {
+		Throttled() (time.Duration, bool)
+	}
+	if errors.As(err, &throttled) {
+		return throttled.Throttled()
+	}
+	return 0, false
+}
+
+// ParseRetryAfter parses the value of the Retry-After header in delay seconds or HTTP date
+func ParseRetryAfter(value string, now time.Time) (time.Duration, bool) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return 0, false
+	}
+	if seconds, err := strconv.Atoi(value); err == nil {
+		return time.Duration(max(seconds, 0)) * time.Second, true
+	}
+	if t, err := http.ParseTime(value); err == nil {
+		return max(t.Sub(now), 0), true
+	}
+	return 0, false
+}
+
+// AdaptiveOptions is the options of the adaptive limiter
+type AdaptiveOptions struct {
+	Rate     float64 // initial tokens per second
+	MinRate  float64 // default Rate/10
+	MaxRate  float64 // default Rate
+	Burst    int
+	Decrease float64 // factor to multiply the rate when throttled, default 0.5
+	Increase float64 // tokens per second added after each success, default MaxRate/100
+}
+
+// AdaptiveLimiter is a token bucket shrinking the rate when the calls are throttled and
+// growing it back on success, and it pauses all calls for the Retry-After of the server.
+type AdaptiveLimiter struct {
+	bucket      *TokenBucket
+	opts        AdaptiveOptions
+	mu          sync.Mutex
+	pausedUntil time.Time
+}
+
+// NewAdaptiveLimiter returns an adaptive limiter starting at opts.Rate, it panics when
+// opts.Rate is not positive
+func NewAdaptiveLimiter(opts AdaptiveOptions) *AdaptiveLimiter {
+	if !(opts.Rate > 0) {
+		panic("hooks: adaptive limiter rate must be positive")
+	}
+	if opts.MaxRate <= 0 {
+		opts.MaxRate = opts.Rate
+	}
+	if opts.MinRate <= 0 {
+		opts.MinRate = opts.Rate / 10
+	}
+	if opts.Decrease <= 0 || opts.Decrease >= 1 {
+		opts.Decrease = 0.5
+	}
+	if opts.Increase <= 0 {
+		opts.Increase = opts.MaxRate / 100
+	}
+	return &AdaptiveLimiter{
+		bucket: NewTokenBucket(opts.Rate, opts.Burst),
+		opts:   opts,
+	}
+}
+
+// Rate returns the current tokens per second
+func (l *AdaptiveLimiter) Rate() float64 {
+	return l.bucket.Rate()
+}
+
+func (l *AdaptiveLimiter) pause() time.Duration {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.pausedUntil.Sub(l.bucket.now())
+}
+
+// Allow takes a permit without waiting
+func (l *AdaptiveLimiter) Allow() bool {
+	return l.pause() <= 0 && l.bucket.Allow()
+}
+
+// Wait waits for the pause of Retry-After and then a permit
+func (l *AdaptiveLimiter) Wait(ctx context.Context) error {
+	if pause := l.pause(); pause > 0 {
+		if exceedsDeadline(ctx, pause) {
+			return ErrRateLimited
+		}
+		if err := sleepUntil(ctx, pause); err != nil {
+			return err
+		}
+	}
+	return l.bucket.Wait(ctx)
+}
+
+// Observe adapts the rate to the result of a call, the throttled errors shrink the rate
+// and the successes grow it, the other errors are ignored.
+func (l *AdaptiveLimiter) Observe(err error) {
+	if err == nil {
+		l.bucket.updateRate(func(rate float64) float64 {
+			return math.Min(l.opts.MaxRate, rate+l.opts.Increase)
+		})
+		return
+	}
+	retryAfter, ok := IsThrottled(err)
+	if !ok {
+		return
+	}
+	l.bucket.updateRate(func(rate float64) float64 {
+		return math.Max(l.opts.MinRate, rate*l.opts.Decrease)
+	})
+	if retryAfter > 0 {
+		l.mu.Lock()
+		if until := l.bucket.now().Add(retryAfter); until.After(l.pausedUntil) {
+			l.pausedUntil = until
+		}
+		l.mu.Unlock()
+	}
+}
+
+// KeyedLimiter holds a limiter per key, such as per user or per host, the limiters unused
+// for the idle timeout are evicted.
+type KeyedLimiter struct {
+	group *Group[Limiter]
+}
+
+// NewKeyedLimiter returns a keyed limiter creating the limiters by newFn,
+// idleTimeout 0 means never evicting.
+func NewKeyedLimiter(newFn func(key string) Limiter, idleTimeout time.Duration) *KeyedLimiter {
+	group := NewGroup(newFn)
+	group.SetIdleTimeout(idleTimeout)
+	return &KeyedLimiter{group: group}
+}
+
+// Get returns the limiter of the key
+func (k *KeyedLimiter) Get(key string) Limiter {
+	return k.group.Get(key)
+}
+
+// Allow takes a permit of the key without waiting
+func (k *KeyedLimiter) Allow(key string) bool {
+	return k.group.Get(key).Allow()
+}
+
+// Wait waits for a permit of the key
+func (k *KeyedLimiter) Wait(ctx context.Context, key string) error {
+	return k.group.Get(key).Wait(ctx)
+}
+
+// Len returns the number of the limiters
+func (k *KeyedLimiter) Len() int {
+	return len(k.group.Keys())
+}
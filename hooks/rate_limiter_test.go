@@ -0,0 +1,229 @@
+package hooks
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestTokenBucketAllow(t *testing.T) {
+	clock := newManualClock()
+	b := NewTokenBucket(2, 3)
+	b.now = clock.Now
+	for i := 0; i < 3; i++ {
+		if !b.Allow() {
+			t.Fatalf("burst %d should be allowed", i)
+		}
+	}
+	if b.Allow() {
+		t.Fatal("empty bucket should not allow")
+	}
+	clock.Advance(500 * time.Millisecond)
+	if !b.Allow() || b.Allow() {
+		t.Fatal("one token should be refilled in half a second")
+	}
+	clock.Advance(time.Hour)
+	for i := 0; i < 3; i++ {
+		b.Allow()
+	}
+	if b.Allow() {
+		t.Fatal("refill should be capped by the burst")
+	}
+}
+
+func TestTokenBucketWait(t *testing.T) {
+	b := NewTokenBucket(100, 1)
+	start := time.Now()
+	for i := 0; i < 5; i++ {
+		if err := b.Wait(context.Background()); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if elapsed := time.Since(start); elapsed < 35*time.Millisecond {
+		t.Fatalf("waits should be spaced by the rate, elapsed %v", elapsed)
+	}
+
+	slow := NewTokenBucket(1, 1)
+	slow.Allow()
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if err := slow.Wait(ctx); !errors.Is(err, ErrRateLimited) {
+		t.Fatalf("token after the deadline should be rejected, got %v", err)
+	}
+}
+
+func TestLeakyBucket(t *testing.T) {
+	clock := newManualClock()
+	b := NewLeakyBucket(10, 2)
+	b.now = clock.Now
+	if !b.Allow() || b.Allow() {
+		t.Fatal("leaky bucket should not burst")
+	}
+	clock.Advance(100 * time.Millisecond)
+	if !b.Allow() {
+		t.Fatal("call should be allowed after the interval")
+	}
+
+	real := NewLeakyBucket(100, 2)
+	ctx := context.Background()
+	start := time.Now()
+	for i := 0; i < 3; i++ {
+		if err := real.Wait(ctx); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if elapsed := time.Since(start); elapsed < 15*time.Millisecond {
+		t.Fatalf("calls should be spaced, elapsed %v", elapsed)
+	}
+	full := NewLeakyBucket(1, 1)
+	full.Wait(ctx)
+	go full.Wait(ctx)
+	time.Sleep(5 * time.Millisecond)
+	if err := full.Wait(ctx); !errors.Is(err, ErrRateLimited) {
+		t.Fatalf("full queue should reject, got %v", err)
+	}
+
+}
+
+func TestLeakyBucketWaitCanceled(t *testing.T) {
+	b := NewLeakyBucket(1, 1)
+	b.Wait(context.Background())
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(10*time.Millisecond, cancel)
+	if err := b.Wait(ctx); !errors.Is(err, context.Canceled) {
+		t.Fatalf("wait should be canceled, got %v", err)
+	}
+	// the canceled turn is given back, so the queue has room for the next call
+	ctx, cancel = context.WithCancel(context.Background())
+	time.AfterFunc(10*time.Millisecond, cancel)
+	if err := b.Wait(ctx); !errors.Is(err, context.Canceled) {
+		t.Fatalf("the next call should be queued, got %v", err)
+	}
+}
+
+func TestLimiterInvalidRate(t *testing.T) {
+	constructors := map[string]func(rate float64){
+		"token bucket":     func(rate float64) { NewTokenBucket(rate, 1) },
+		"leaky bucket":     func(rate float64) { NewLeakyBucket(rate, 1) },
+		"adaptive limiter": func(rate float64) { NewAdaptiveLimiter(AdaptiveOptions{Rate: rate, Burst: 1}) },
+	}
+	for name, newFn := range constructors {
+		for _, rate := range []float64{0, -1} {
+			func() {
+				defer func() {
+					if recover() == nil {
+						t.Fatalf("%s should reject rate %v", name, rate)
+					}
+				}()
+				newFn(rate)
+			}()
+		}
+	}
+}
+
+func TestAdaptiveLimiter(t *testing.T) {
+	clock := newManualClock()
+	l := NewAdaptiveLimiter(AdaptiveOptions{Rate: 100, MinRate: 10, Burst: 1, Increase: 5})
+	l.bucket.now = clock.Now
+
+	throttled := Throttled(errors.New("429"), 2*time.Second)
+	l.Observe(throttled)
+	if l.Rate() != 50 {
+		t.Fatalf("rate should be halved, got %v", l.Rate())
+	}
+	if l.Allow() {
+		t.Fatal("limiter should pause for the retry after")
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if err := l.Wait(ctx); !errors.Is(err, ErrRateLimited) {
+		t.Fatalf("wait beyond the deadline should be rejected, got %v", err)
+	}
+	clock.Advance(2 * time.Second)
+	if !l.Allow() {
+		t.Fatal("limiter should resume after the retry after")
+	}
+
+	for i := 0; i < 5; i++ {
+		l.Observe(throttled)
+	}
+	if l.Rate() != 10 {
+		t.Fatalf("rate should be bounded by the min rate, got %v", l.Rate())
+	}
+	l.Observe(errors.New("other"))
+	l.Observe(nil)
+	if l.Rate() != 15 {
+		t.Fatalf("success should grow the rate, got %v", l.Rate())
+	}
+}
+
+func TestAdaptiveLimiterConcurrentObserve(t *testing.T) {
+	l := NewAdaptiveLimiter(AdaptiveOptions{Rate: 10, MaxRate: 1000, Burst: 1, Increase: 1})
+	var wg sync.WaitGroup
+	for i := 0; i < 100; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			l.Observe(nil)
+		}()
+	}
+	wg.Wait()
+	if l.Rate() != 110 {
+		t.Fatalf("concurrent successes should all grow the rate, got %v", l.Rate())
+	}
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	if d, ok := ParseRetryAfter("120", now); !ok || d != 2*time.Minute {
+		t.Fatalf("unexpected delay %v", d)
+	}
+	if d, ok := ParseRetryAfter(now.Add(30*time.Second).Format(http.TimeFormat), now); !ok || d != 30*time.Second {
+		t.Fatalf("unexpected delay %v", d)
+	}
+	if _, ok := ParseRetryAfter("soon", now); ok {
+		t.Fatal("invalid value should be rejected")
+	}
+}
+
+func TestKeyedLimiter(t *testing.T) {
+	clock := newManualClock()
+	k := NewKeyedLimiter(func(key string) Limiter { return NewTokenBucket(1, 1) }, time.Minute)
+	k.group.now = clock.Now
+	if !k.Allow("a") || k.Allow("a") || !k.Allow("b") {
+		t.Fatal("limiters should be separated by key")
+	}
+	clock.Advance(30 * time.Second)
+	k.Get("b")
+	clock.Advance(40 * time.Second)
+	k.Get("c")
+	if k.Len() != 2 {
+		t.Fatalf("idle limiter a should be evicted, got %d", k.Len())
+	}
+}
+
+func TestRunWithRetryLimiterAndRetryAfter(t *testing.T) {
+	limiter := NewAdaptiveLimiter(AdaptiveOptions{Rate: 1000, Burst: 10})
+	calls := 0
+	start := time.Now()
+	err := RunWithRetry(context.Background(), "task", func(ctx context.Context) error {
+		calls++
+		if calls == 1 {
+			return Throttled(fmt.Errorf("too many requests"), 30*time.Millisecond)
+		}
+		return nil
+	}, CreateFixedIntervals(3, time.Millisecond), WithLimiter(limiter), WithLogger(NopLogger))
+	if err != nil || calls != 2 {
+		t.Fatalf("unexpected result %v after %d calls", err, calls)
+	}
+	if time.Since(start) < 30*time.Millisecond {
+		t.Fatal("retry should wait for the retry after")
+	}
+	if limiter.Rate() >= 1000 {
+		t.Fatal("limiter should observe the throttled error")
+	}
+}
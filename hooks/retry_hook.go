@@ -2,8 +2,6 @@ package hooks
 
 import (
 	"context"
-	"fmt"
-	"log"
 	"time"
 )
 
@@ -52,27 +50,27 @@ func createFibonacciInts(max uint) []uint {
 	}
 }
 
-func RunWithRetry(ctx context.Context, taskName string, fn func(context.Context) error, retryIntervals []time.Duration) (err error) {
-	log.Println(taskName, "run first...")
-	// first run
-	err = fn(ctx)
-	if err == nil {
-		return
+// RunWithRetry runs fn and retries after each interval until it succeeds, the errors except
+// the context ones are retried, and the progress is printed by the stdlib log package.
+// The options customize the policy, such as WithLimiter and WithLogger.
+func RunWithRetry(ctx context.Context, taskName string, fn func(context.Context) error, retryIntervals []time.Duration, opts ...RetryOption) (err error) {
+	return newHookPolicy(retryIntervals, opts).Run(ctx, taskName, fn)
+}
+
+// RunWithRetryValue is RunWithRetry for fn returning a value, see Retry for the value and the error
+func RunWithRetryValue[T any](ctx context.Context, taskName string, fn func(context.Context) (T, error), retryIntervals []time.Duration, opts ...RetryOption) (T, error) {
+	value, attempts, err := retry(ctx, newHookPolicy(retryIntervals, opts), taskName, fn)
+	if err != nil {
+		return value, newRetryError(attempts, err)
 	}
-	// retry logic
-	for index, interval := range retryIntervals {
-		select {
-		case <-ctx.Done():
-			log.Println(taskName, "run canceled ...")
-			err = context.Canceled
-			return
-		case <-time.After(interval):
-			log.Println(taskName, fmt.Sprintf("retry run %d times ...", index+1))
-			err = fn(ctx)
-			if err == nil || err == context.Canceled || err == context.DeadlineExceeded {
-				return
-			}
-		}
+	return value, nil
+}
+
+func newHookPolicy(retryIntervals []time.Duration, opts []RetryOption) *RetryPolicy {
+	policy := NewIntervalPolicy(retryIntervals)
+	policy.Logger = StdLogger
+	for _, opt := range opts {
+		opt(policy)
 	}
-	return
+	return policy
 }
@@ -34,9 +34,41 @@ func print(ctx context.Context) (err error) {
 func TestRetryFail(t *testing.T) {
 	ctx, cancelFunc := context.WithCancel(context.Background())
 	go func() {
-		<-time.After(time.Second * 20)
+		<-time.After(time.Millisecond * 200)
 		cancelFunc()
 	}()
-	err := RunWithRetry(ctx, print, CreateFibonacciIntervals(10, time.Second*3))
+	err := RunWithRetry(ctx, "print", print, CreateFibonacciIntervals(10, time.Millisecond*30))
 	t.Logf("run error= %v", err)
 }
+
+func TestRunWithRetryNoIntervals(t *testing.T) {
+	errFail := errors.New("fail")
+	for _, intervals := range [][]time.Duration{nil, {}} {
+		calls := 0
+		err := RunWithRetry(context.Background(), "once", func(ctx context.Context) error {
+			calls++
+			return errFail
+		}, intervals, WithLogger(NopLogger))
+		if err != errFail || calls != 1 {
+			t.Fatalf("task should run once without intervals, got %v after %d calls", err, calls)
+		}
+	}
+
+	calls := 0
+	(&RetryPolicy{}).Run(context.Background(), "zero", func(ctx context.Context) error {
+		calls++
+		return errFail
+	})
+	if calls != 1 {
+		t.Fatalf("zero policy should not retry, got %d calls", calls)
+	}
+}
+
+func TestRunWithRetryLogitLoggerBeforeInit(t *testing.T) {
+	err := RunWithRetry(context.Background(), "logit", func(ctx context.Context) error {
+		return nil
+	}, nil, WithLogger(LogitLogger))
+	if err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,333 @@
+package hooks
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"math/rand/v2"
+	"time"
+)
+
+// Jitter is the strategy to randomize the retry delays, which spreads the retries of many clients
+type Jitter int
+
+const (
+	NoJitter           Jitter = iota
+	FullJitter                // random in [0, delay]
+	EqualJitter               // delay/2 plus random in [0, delay/2]
+	DecorrelatedJitter        // random in [InitialDelay, 3*previous delay]
+)
+
+// PermanentError wraps an error which should not be retried
+type PermanentError struct {
+	Err error
+}
+
+func (e *PermanentError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *PermanentError) Unwrap() error {
+	return e.Err
+}
+
+// Permanent wraps the error so the retry stops immediately, nil is returned for nil
+func Permanent(err error) error {
+	if err == nil {
+		return nil
+	}
+	return &PermanentError{Err: err}
+}
+
+// IsPermanent returns true when the error is wrapped by Permanent
+func IsPermanent(err error) bool {
+	var permanent *PermanentError
+	return errors.As(err, &permanent)
+}
+
+// Attempt is the result of an attempt
+type Attempt struct {
+	Number  int           // starts from 1
+	Err     error         // nil when succeeded
+	Delay   time.Duration // delay before the next attempt, 0 when there is no more attempt
+	Elapsed time.Duration // elapsed time since the first attempt started
+}
+
+// RetryPolicy decides whether and when to retry a failed task.
+//
+// The delays come from Intervals when set, such as the ones created by CreateFixedIntervals,
+// CreateLinearIntervals and CreateFibonacciIntervals, otherwise they grow exponentially from
+// InitialDelay by Multiplier. The delays are randomized by Jitter and capped by MaxDelay.
+type RetryPolicy struct {
+	MaxAttempts  int             // max attempts including the first one, 0 means len(Intervals)+1, or unlimited with a positive InitialDelay
+	Intervals    []time.Duration // fixed delays of the retries
+	InitialDelay time.Duration   // delay of the first retry
+	MaxDelay     time.Duration   // cap of each delay, 0 means no cap
+	Multiplier   float64         // growth factor of the delays, default 2
+	Jitter       Jitter
+	MaxElapsed   time.Duration         // no more retry when the total elapsed time would exceed it, 0 means no limit
+	Retryable    func(err error) bool  // classifies the errors, default all errors except the context errors
+	OnAttempt    func(attempt Attempt) // called after each attempt
+	Logger       Logger                // default NopLogger
+	Limiter      Limiter               // waited before each attempt, and observes the results when it is an Observer
+	HedgeDelay   time.Duration         // launches a speculative call when an attempt is slower, 0 disables hedging, see Hedge
+}
+
+// RetryError is the error of Retry, it joins the errors of all the attempts so errors.Is and
+// errors.As match any of them, and keeps the attempts for inspection.
+type RetryError struct {
+	Attempts []Attempt
+	err      error
+}
+
+func newRetryError(attempts []Attempt, final error) *RetryError {
+	errs := make([]error, 0, len(attempts)+1)
+	for _, a := range attempts {
+		errs = append(errs, fmt.Errorf("attempt %d: %w", a.Number, unwrapPermanent(a.Err)))
+	}
+	if len(attempts) == 0 || final != unwrapPermanent(attempts[len(attempts)-1].Err) {
+		errs = append(errs, final)
+	}
+	return &RetryError{Attempts: attempts, err: errors.Join(errs...)}
+}
+
+func (e *RetryError) Error() string {
+	return e.err.Error()
+}
+
+func (e *RetryError) Unwrap() error {
+	return e.err
+}
+
+// Last returns the error of the last attempt
+func (e *RetryError) Last() error {
+	if len(e.Attempts) == 0 {
+		return nil
+	}
+	return unwrapPermanent(e.Attempts[len(e.Attempts)-1].Err)
+}
+
+// RetryOption customizes the policy of RunWithRetry
+type RetryOption func(policy *RetryPolicy)
+
+// WithLimiter waits for the limiter before each attempt
+func WithLimiter(limiter Limiter) RetryOption {
+	return func(policy *RetryPolicy) {
+		policy.Limiter = limiter
+	}
+}
+
+// WithLogger prints the progress with the logger
+func WithLogger(logger Logger) RetryOption {
+	return func(policy *RetryPolicy) {
+		policy.Logger = logger
+	}
+}
+
+// WithRetryable classifies the errors to retry
+func WithRetryable(retryable func(err error) bool) RetryOption {
+	return func(policy *RetryPolicy) {
+		policy.Retryable = retryable
+	}
+}
+
+// WithJitter randomizes the intervals
+func WithJitter(jitter Jitter) RetryOption {
+	return func(policy *RetryPolicy) {
+		policy.Jitter = jitter
+	}
+}
+
+// WithHedge launches a speculative call when an attempt is slower than the delay
+func WithHedge(delay time.Duration) RetryOption {
+	return func(policy *RetryPolicy) {
+		policy.HedgeDelay = delay
+	}
+}
+
+// NewIntervalPolicy returns a policy retrying after the intervals, which is the behavior of RunWithRetry,
+// the task runs only once when there is no interval
+func NewIntervalPolicy(intervals []time.Duration) *RetryPolicy {
+	return &RetryPolicy{
+		MaxAttempts: len(intervals) + 1,
+		Intervals:   append(make([]time.Duration, 0, len(intervals)), intervals...),
+	}
+}
+
+// NewExponentialPolicy returns a policy with exponential backoff and full jitter
+func NewExponentialPolicy(initialDelay, maxDelay time.Duration, maxAttempts int) *RetryPolicy {
+	return &RetryPolicy{
+		MaxAttempts:  maxAttempts,
+		InitialDelay: initialDelay,
+		MaxDelay:     maxDelay,
+		Multiplier:   2,
+		Jitter:       FullJitter,
+	}
+}
+
+// IsRetryable returns true when the error should be retried
+func (p *RetryPolicy) IsRetryable(err error) bool {
+	if err == nil || IsPermanent(err) {
+		return false
+	}
+	if p.Retryable != nil {
+		return p.Retryable(err)
+	}
+	return !errors.Is(err, context.Canceled) && !errors.Is(err, context.DeadlineExceeded)
+}
+
+// Delay returns the delay before the retry after the attempt, ok is false when there is no more retry.
+// The previous delay is used by DecorrelatedJitter.
+func (p *RetryPolicy) Delay(attempt int, prev time.Duration) (delay time.Duration, ok bool) {
+	if p.MaxAttempts > 0 && attempt >= p.MaxAttempts {
+		return 0, false
+	}
+	// never retry without limit and delay, such as the zero policy
+	if p.MaxAttempts <= 0 && p.Intervals == nil && p.InitialDelay <= 0 {
+		return 0, false
+	}
+	if p.Intervals != nil {
+		if attempt > len(p.Intervals) {
+			return 0, false
+		}
+		delay = p.Intervals[attempt-1]
+	} else {
+		multiplier := p.Multiplier
+		if multiplier <= 0 {
+			multiplier = 2
+		}
+		// float64(math.MaxInt64) rounds up to 2^63, so clamp before the conversion
+		if f := float64(p.InitialDelay) * math.Pow(multiplier, float64(attempt-1)); f >= float64(math.MaxInt64) {
+			delay = math.MaxInt64
+		} else {
+			delay = time.Duration(f)
+		}
+	}
+	delay = p.capDelay(delay)
+	switch p.Jitter {
+	case FullJitter:
+		delay = randomDuration(0, delay)
+	case EqualJitter:
+		delay = delay/2 + randomDuration(0, delay-delay/2)
+	case DecorrelatedJitter:
+		base := p.InitialDelay
+		if p.Intervals != nil {
+			base = delay
+		}
+		upper := max(min(prev, math.MaxInt64/3)*3, base)
+		delay = p.capDelay(randomDuration(base, upper))
+	}
+	return delay, true
+}
+
+func (p *RetryPolicy) capDelay(delay time.Duration) time.Duration {
+	if p.MaxDelay > 0 && delay > p.MaxDelay {
+		return p.MaxDelay
+	}
+	return delay
+}
+
+// randomDuration returns a random duration in [low, high]
+func randomDuration(low, high time.Duration) time.Duration {
+	if high <= low {
+		return low
+	}
+	return low + rand.N(high-low+1)
+}
+
+func (p *RetryPolicy) logger() Logger {
+	if p.Logger == nil {
+		return NopLogger
+	}
+	return p.Logger
+}
+
+// Run runs fn until it succeeds, the error is not retryable, the attempts are used up or ctx is done.
+// The error of the last attempt is returned, the Permanent wrapper is removed, and ctx.Err() is
+// returned when ctx is done while waiting. The delay is extended to the Retry-After of the
+// throttled errors, see IsThrottled.
+func (p *RetryPolicy) Run(ctx context.Context, taskName string, fn func(context.Context) error) error {
+	_, _, err := retry(ctx, p, taskName, func(ctx context.Context) (struct{}, error) {
+		return struct{}{}, fn(ctx)
+	})
+	return err
+}
+
+// Retry runs fn with the policy like RetryPolicy.Run and returns the value of the last attempt,
+// taskName is printed in the logs. The error is a *RetryError joining the errors of all the
+// attempts, and ctx.Err() when ctx is done while waiting.
+func Retry[T any](ctx context.Context, policy *RetryPolicy, taskName string, fn func(context.Context) (T, error)) (T, error) {
+	value, attempts, err := retry(ctx, policy, taskName, fn)
+	if err != nil {
+		return value, newRetryError(attempts, err)
+	}
+	return value, nil
+}
+
+func retry[T any](ctx context.Context, p *RetryPolicy, taskName string, fn func(context.Context) (T, error)) (value T, attempts []Attempt, err error) {
+	logger := p.logger()
+	start := time.Now()
+	var prev time.Duration
+	for n := 1; ; n++ {
+		if n == 1 {
+			logger.Printf("%s run first ...", taskName)
+		} else {
+			logger.Printf("%s retry run %d times ...", taskName, n-1)
+		}
+		value, err = Hedge(ctx, p.HedgeDelay, func(ctx context.Context) (T, error) {
+			return attempt(ctx, p.Limiter, fn)
+		})
+		result := Attempt{Number: n, Err: err, Elapsed: time.Since(start)}
+		next := p.IsRetryable(err)
+		if next {
+			result.Delay, next = p.Delay(n, prev)
+			if retryAfter, throttled := IsThrottled(err); throttled && retryAfter > result.Delay {
+				result.Delay = retryAfter
+			}
+			if next && p.MaxElapsed > 0 && result.Elapsed+result.Delay > p.MaxElapsed {
+				result.Delay, next = 0, false
+			}
+		}
+		attempts = append(attempts, result)
+		if p.OnAttempt != nil {
+			p.OnAttempt(result)
+		}
+		if !next {
+			return value, attempts, unwrapPermanent(err)
+		}
+
+		timer := time.NewTimer(result.Delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			logger.Printf("%s run canceled ...", taskName)
+			return value, attempts, ctx.Err()
+		case <-timer.C:
+		}
+		prev = result.Delay
+	}
+}
+
+func attempt[T any](ctx context.Context, limiter Limiter, fn func(context.Context) (T, error)) (T, error) {
+	if limiter == nil {
+		return fn(ctx)
+	}
+	if err := limiter.Wait(ctx); err != nil {
+		var zero T
+		return zero, err
+	}
+	value, err := fn(ctx)
+	if observer, ok := limiter.(Observer); ok {
+		observer.Observe(err)
+	}
+	return value, err
+}
+
+func unwrapPermanent(err error) error {
+	var permanent *PermanentError
+	if errors.As(err, &permanent) {
+		return permanent.Err
+	}
+	return err
+}
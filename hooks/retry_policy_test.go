@@ -0,0 +1,220 @@
+package hooks
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+var errTemporary = errors.New("temporary")
+
+func TestRetryPolicyDelay(t *testing.T) {
+	p := &RetryPolicy{InitialDelay: 100 * time.Millisecond, MaxDelay: time.Second, MaxAttempts: 6}
+	want := []time.Duration{100, 200, 400, 800, 1000}
+	for i, w := range want {
+		d, ok := p.Delay(i+1, 0)
+		if !ok || d != w*time.Millisecond {
+			t.Fatalf("attempt %d: unexpected delay %v", i+1, d)
+		}
+	}
+	if _, ok := p.Delay(6, 0); ok {
+		t.Fatal("no retry after max attempts")
+	}
+
+	intervals := NewIntervalPolicy(CreateLinearIntervals(3, time.Second))
+	if d, ok := intervals.Delay(3, 0); !ok || d != 3*time.Second {
+		t.Fatalf("unexpected interval delay %v", d)
+	}
+	if _, ok := intervals.Delay(4, 0); ok {
+		t.Fatal("no retry after the intervals")
+	}
+	unlimited := &RetryPolicy{InitialDelay: time.Second}
+	for _, attempt := range []int{40, 64, 100, 1000, math.MaxInt32} {
+		if d, ok := unlimited.Delay(attempt, 0); !ok || d != math.MaxInt64 {
+			t.Fatalf("attempt %d: delay should be clamped, got %v", attempt, d)
+		}
+	}
+	decorrelated := &RetryPolicy{InitialDelay: time.Second, Jitter: DecorrelatedJitter}
+	if d, _ := decorrelated.Delay(1000, math.MaxInt64); d < time.Second {
+		t.Fatalf("decorrelated delay should not overflow, got %v", d)
+	}
+}
+
+func TestRetryPolicyJitter(t *testing.T) {
+	for i := 0; i < 100; i++ {
+		full := &RetryPolicy{InitialDelay: time.Second, Jitter: FullJitter}
+		if d, _ := full.Delay(2, 0); d < 0 || d > 2*time.Second {
+			t.Fatalf("full jitter out of range %v", d)
+		}
+		equal := &RetryPolicy{InitialDelay: time.Second, Jitter: EqualJitter}
+		if d, _ := equal.Delay(2, 0); d < time.Second || d > 2*time.Second {
+			t.Fatalf("equal jitter out of range %v", d)
+		}
+		decorrelated := &RetryPolicy{InitialDelay: time.Second, MaxDelay: 5 * time.Second, Jitter: DecorrelatedJitter}
+		if d, _ := decorrelated.Delay(3, time.Second); d < time.Second || d > 3*time.Second {
+			t.Fatalf("decorrelated jitter out of range %v", d)
+		}
+		if d, _ := decorrelated.Delay(3, 4*time.Second); d > 5*time.Second {
+			t.Fatalf("decorrelated jitter should be capped, got %v", d)
+		}
+	}
+}
+
+func TestRetryPolicyRun(t *testing.T) {
+	var attempts []Attempt
+	var logs []string
+	p := &RetryPolicy{
+		Intervals: CreateFixedIntervals(5, time.Millisecond),
+		OnAttempt: func(a Attempt) { attempts = append(attempts, a) },
+		Logger:    LoggerFunc(func(format string, args ...any) { logs = append(logs, fmt.Sprintf(format, args...)) }),
+	}
+	calls := 0
+	err := p.Run(context.Background(), "task", func(ctx context.Context) error {
+		calls++
+		if calls < 3 {
+			return errTemporary
+		}
+		return nil
+	})
+	if err != nil || calls != 3 {
+		t.Fatalf("unexpected result %v after %d calls", err, calls)
+	}
+	if len(attempts) != 3 || attempts[0].Err == nil || attempts[0].Delay != time.Millisecond || attempts[2].Err != nil {
+		t.Fatalf("unexpected attempts %+v", attempts)
+	}
+	if len(logs) != 3 || logs[0] != "task run first ..." || !strings.Contains(logs[2], "retry run 2 times") {
+		t.Fatalf("unexpected logs %v", logs)
+	}
+}
+
+func TestRetryPolicyClassification(t *testing.T) {
+	errFatal := errors.New("fatal")
+	calls := 0
+	err := NewExponentialPolicy(time.Millisecond, time.Millisecond, 5).Run(context.Background(), "task", func(ctx context.Context) error {
+		calls++
+		return Permanent(errFatal)
+	})
+	if err != errFatal || calls != 1 {
+		t.Fatalf("permanent error should stop the retry, got %v after %d calls", err, calls)
+	}
+
+	calls = 0
+	p := NewExponentialPolicy(time.Millisecond, time.Millisecond, 5)
+	p.Retryable = func(err error) bool { return errors.Is(err, errTemporary) }
+	err = p.Run(context.Background(), "task", func(ctx context.Context) error {
+		calls++
+		if calls == 2 {
+			return errFatal
+		}
+		return errTemporary
+	})
+	if err != errFatal || calls != 2 {
+		t.Fatalf("classifier should stop the retry, got %v after %d calls", err, calls)
+	}
+
+	calls = 0
+	err = NewExponentialPolicy(time.Millisecond, time.Millisecond, 3).Run(context.Background(), "task", func(ctx context.Context) error {
+		calls++
+		return errTemporary
+	})
+	if err != errTemporary || calls != 3 {
+		t.Fatalf("attempts should be used up, got %v after %d calls", err, calls)
+	}
+}
+
+func TestRetryPolicyMaxElapsedAndCancel(t *testing.T) {
+	p := &RetryPolicy{InitialDelay: 20 * time.Millisecond, Multiplier: 1, MaxElapsed: 50 * time.Millisecond}
+	calls := 0
+	start := time.Now()
+	err := p.Run(context.Background(), "task", func(ctx context.Context) error {
+		calls++
+		return errTemporary
+	})
+	if err != errTemporary || calls != 3 || time.Since(start) > 50*time.Millisecond {
+		t.Fatalf("max elapsed should stop the retry, got %v after %d calls", err, calls)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	err = NewIntervalPolicy(CreateFixedIntervals(3, time.Second)).Run(ctx, "task", func(ctx context.Context) error {
+		return errTemporary
+	})
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("unexpected error %v", err)
+	}
+}
+
+func TestRetryValue(t *testing.T) {
+	var logs []string
+	p := &RetryPolicy{MaxAttempts: 3, InitialDelay: time.Millisecond, Logger: LoggerFunc(func(format string, args ...any) {
+		logs = append(logs, fmt.Sprintf(format, args...))
+	})}
+	calls := 0
+	value, err := Retry(context.Background(), p, "answer", func(ctx context.Context) (int, error) {
+		calls++
+		if calls < 3 {
+			return 0, errTemporary
+		}
+		return 42, nil
+	})
+	if err != nil || value != 42 {
+		t.Fatalf("unexpected result %d, %v", value, err)
+	}
+	if len(logs) == 0 || !strings.HasPrefix(logs[0], "answer ") {
+		t.Fatalf("logs should carry the task name, got %v", logs)
+	}
+
+	errLast := errors.New("last")
+	calls = 0
+	_, err = Retry(context.Background(), p, "task", func(ctx context.Context) (int, error) {
+		calls++
+		if calls == 3 {
+			return -1, errLast
+		}
+		return calls, errTemporary
+	})
+	var retryErr *RetryError
+	if !errors.As(err, &retryErr) || len(retryErr.Attempts) != 3 || retryErr.Last() != errLast {
+		t.Fatalf("unexpected error %#v", err)
+	}
+	if !errors.Is(err, errTemporary) || !errors.Is(err, errLast) {
+		t.Fatal("error should join the errors of all attempts")
+	}
+	if !strings.Contains(err.Error(), "attempt 1: temporary") || !strings.Contains(err.Error(), "attempt 3: last") {
+		t.Fatalf("unexpected message %q", err.Error())
+	}
+
+	_, err = Retry(context.Background(), p, "task", func(ctx context.Context) (string, error) {
+		return "", Permanent(errLast)
+	})
+	if !errors.As(err, &retryErr) || len(retryErr.Attempts) != 1 || IsPermanent(err) {
+		t.Fatalf("permanent error should stop the retry, got %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Millisecond)
+	defer cancel()
+	slow := &RetryPolicy{InitialDelay: time.Second}
+	_, err = Retry(ctx, slow, "task", func(ctx context.Context) (int, error) { return 0, errTemporary })
+	if !errors.Is(err, context.DeadlineExceeded) || !errors.Is(err, errTemporary) {
+		t.Fatalf("canceled retry should keep the attempt errors, got %v", err)
+	}
+}
+
+func TestRunWithRetryValueHedge(t *testing.T) {
+	var calls atomic.Int32
+	value, err := RunWithRetryValue(context.Background(), "hedge", func(ctx context.Context) (string, error) {
+		if calls.Add(1) == 1 {
+			<-ctx.Done()
+			return "", ctx.Err()
+		}
+		return "fast", nil
+	}, CreateFixedIntervals(1, time.Millisecond), WithHedge(5*time.Millisecond), WithLogger(NopLogger))
+	if err != nil || value != "fast" || calls.Load() != 2 {
+		t.Fatalf("hedged call should win, got %q, %v after %d calls", value, err, calls.Load())
+	}
+}
@@ -0,0 +1,110 @@
+package logit
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/duoland/base/utils/trace"
+	"go.uber.org/zap"
+)
+
+const (
+	// RequestIDHeader is the header carrying the request id, the same as rpc.XHeaderLogID
+	RequestIDHeader = "X-Request-ID"
+	// RequestIDField is the log field of the request id
+	RequestIDField = "reqid"
+)
+
+type contextKey int
+
+const (
+	requestIDKey contextKey = iota
+	fieldsKey
+	loggerKey
+)
+
+// WithRequestID returns a context carrying the request id, a new one is created by trace.GenReqID
+// when reqID is empty. rpc.APIClient forwards the id by RequestID.
+func WithRequestID(ctx context.Context, reqID string) context.Context {
+	if reqID == "" {
+		reqID = trace.GenReqID()
+	}
+	return context.WithValue(ctx, requestIDKey, reqID)
+}
+
+// RequestID returns the request id carried by ctx, empty when there is none
+func RequestID(ctx context.Context) string {
+	if ctx == nil {
+		return ""
+	}
+	reqID, _ := ctx.Value(requestIDKey).(string)
+	return reqID
+}
+
+// RequestContext returns the context of the request carrying the id in the X-Request-ID header,
+// or a new one when the header is absent
+func RequestContext(req *http.Request) context.Context {
+	return WithRequestID(req.Context(), req.Header.Get(RequestIDHeader))
+}
+
+// Middleware attaches the request id to the context of each request and echoes it in the response header
+func Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		ctx := RequestContext(req)
+		w.Header().Set(RequestIDHeader, RequestID(ctx))
+		next.ServeHTTP(w, req.WithContext(ctx))
+	})
+}
+
+// WithFields returns a context carrying the fields as key-value pairs, which are appended to the
+// fields already in ctx and included by the loggers of WithContext
+func WithFields(ctx context.Context, keysAndValues ...interface{}) context.Context {
+	fields := Fields(ctx)
+	merged := make([]interface{}, 0, len(fields)+len(keysAndValues))
+	merged = append(merged, fields...)
+	merged = append(merged, keysAndValues...)
+	return context.WithValue(ctx, fieldsKey, merged)
+}
+
+// Fields returns the fields carried by ctx
+func Fields(ctx context.Context) []interface{} {
+	if ctx == nil {
+		return nil
+	}
+	fields, _ := ctx.Value(fieldsKey).([]interface{})
+	return fields
+}
+
+// NewContext returns a context carrying the logger, which is returned by FromContext
+func NewContext(ctx context.Context, logger *zap.SugaredLogger) context.Context {
+	return context.WithValue(ctx, loggerKey, logger)
+}
+
+// WithContext returns a child logger of Logger with the request id and the fields of ctx,
+// a no-op logger is used before InitLogs
+func WithContext(ctx context.Context) *zap.SugaredLogger {
+	logger := Logger
+	if logger == nil {
+		logger = zap.NewNop().Sugar()
+	}
+	fields := Fields(ctx)
+	args := make([]interface{}, 0, len(fields)+2)
+	if reqID := RequestID(ctx); reqID != "" {
+		args = append(args, RequestIDField, reqID)
+	}
+	args = append(args, fields...)
+	if len(args) == 0 {
+		return logger
+	}
+	return logger.With(args...)
+}
+
+// FromContext returns the logger stored by NewContext, or the logger of WithContext when there is none
+func FromContext(ctx context.Context) *zap.SugaredLogger {
+	if ctx != nil {
+		if logger, ok := ctx.Value(loggerKey).(*zap.SugaredLogger); ok {
+			return logger
+		}
+	}
+	return WithContext(ctx)
+}
@@ -0,0 +1,71 @@
+package logit
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func observeLogs(t *testing.T) *observer.ObservedLogs {
+	core, logs := observer.New(zap.InfoLevel)
+	saved := Logger
+	Logger = zap.New(core).Sugar()
+	t.Cleanup(func() { Logger = saved })
+	return logs
+}
+
+func TestWithContext(t *testing.T) {
+	logs := observeLogs(t)
+	ctx := WithRequestID(context.Background(), "")
+	reqID := RequestID(ctx)
+	if reqID == "" {
+		t.Fatal("request id should be generated")
+	}
+	ctx = WithFields(ctx, "user", "jemy")
+	ctx = WithFields(ctx, "op", "upload")
+	WithContext(ctx).Infow("hello", "size", 10)
+	FromContext(context.Background()).Info("no request")
+
+	entries := logs.All()
+	if len(entries) != 2 {
+		t.Fatalf("unexpected entries %v", entries)
+	}
+	fields := entries[0].ContextMap()
+	if fields[RequestIDField] != reqID || fields["user"] != "jemy" || fields["op"] != "upload" || fields["size"] != int64(10) {
+		t.Fatalf("unexpected fields %v", fields)
+	}
+	if len(entries[1].ContextMap()) != 0 {
+		t.Fatalf("plain context should have no fields, got %v", entries[1].ContextMap())
+	}
+
+	custom := Logger.With("component", "worker")
+	FromContext(NewContext(ctx, custom)).Info("custom")
+	if fields := logs.All()[2].ContextMap(); fields["component"] != "worker" {
+		t.Fatalf("stored logger should be used, got %v", fields)
+	}
+}
+
+func TestMiddleware(t *testing.T) {
+	logs := observeLogs(t)
+	handler := Middleware(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		FromContext(req.Context()).Info("handled")
+	}))
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set(RequestIDHeader, "abc")
+	resp := httptest.NewRecorder()
+	handler.ServeHTTP(resp, req)
+	if resp.Header().Get(RequestIDHeader) != "abc" || logs.All()[0].ContextMap()[RequestIDField] != "abc" {
+		t.Fatal("request id of the header should be propagated")
+	}
+
+	resp = httptest.NewRecorder()
+	handler.ServeHTTP(resp, httptest.NewRequest(http.MethodGet, "/", nil))
+	if reqID := resp.Header().Get(RequestIDHeader); reqID == "" || logs.All()[1].ContextMap()[RequestIDField] != reqID {
+		t.Fatal("request id should be generated when the header is absent")
+	}
+}
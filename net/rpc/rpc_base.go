@@ -1,5 +1,11 @@
 package rpc
 
+import (
+	"fmt"
+	"net/http"
+	"time"
+)
+
 const (
 	XHeaderLogID = "X-Request-ID"
 )
@@ -17,6 +23,40 @@ type APIRet interface {
 	SetRequestID(string)
 }
 
+// StatusError is returned by APIClient.Call when the response status is not 2xx
+type StatusError struct {
+	StatusCode int
+	Status     string
+	Code       string        // the code of the decoded api ret
+	Message    string        // the message of the decoded api ret
+	RetryAfter time.Duration // parsed from the Retry-After header, 0 when absent
+	decoded    bool
+}
+
+func (e *StatusError) Error() string {
+	if !e.decoded {
+		return fmt.Sprintf("status=%s", e.Status)
+	}
+	return fmt.Sprintf("status=%s, error=%s, %s", e.Status, e.Code, e.Message)
+}
+
+// Throttled returns true for 429 and 503, which makes the retry wait for RetryAfter
+// and the adaptive limiter slow down, see hooks.IsThrottled
+func (e *StatusError) Throttled() (time.Duration, bool) {
+	throttled := e.StatusCode == http.StatusTooManyRequests || e.StatusCode == http.StatusServiceUnavailable
+	return e.RetryAfter, throttled
+}
+
+// RetError is returned by APIClient.Call when the status is 2xx but the api ret is not ok
+type RetError struct {
+	Code    string
+	Message string
+}
+
+func (e *RetError) Error() string {
+	return fmt.Sprintf("%s, %s", e.Code, e.Message)
+}
+
 type BaseAPIRet struct {
 	Status    int    `json:"status"`
 	Code      string `json:"code"`
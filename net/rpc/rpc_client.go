@@ -8,11 +8,26 @@ import (
 	"net/http"
 	"net/url"
 	"time"
+
+	"github.com/duoland/base/hooks"
+	"github.com/duoland/base/logit"
+	"github.com/duoland/base/net/sign"
 )
 
 type APIClient struct {
 	client  http.Client
 	traceID string
+	signer  *sign.Signer
+
+	retry     *hooks.RetryPolicy
+	breakers  *hooks.Group[*hooks.CircuitBreaker]
+	bulkheads *hooks.Group[*hooks.Bulkhead]
+	limiters  *hooks.KeyedLimiter
+}
+
+// SetSigner signs every outgoing request with the signer, set nil to disable
+func (c *APIClient) SetSigner(signer *sign.Signer) {
+	c.signer = signer
 }
 
 func (c *APIClient) SetTraceID(traceID string) {
@@ -42,17 +57,33 @@ func (c *APIClient) Call(ctx context.Context, reqUrl, method string, header http
 		err = fmt.Errorf("parse request url error, %s", reqUrl)
 		return
 	}
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	if guard := c.guard(reqURI.Host); guard != nil {
+		return guard.Run(ctx, method+" "+reqURI.Host, func(ctx context.Context) error {
+			return c.call(ctx, reqURI, method, header, query, body, apiRet)
+		})
+	}
+	return c.call(ctx, reqURI, method, header, query, body, apiRet)
+}
+
+func (c *APIClient) call(ctx context.Context, reqURI *url.URL, method string, header http.Header, query url.Values, body []byte, apiRet APIRet) (err error) {
 	if len(query) > 0 {
 		reqURI.RawQuery = query.Encode()
 	}
-	req, newErr := http.NewRequest(method, reqURI.String(), bytes.NewBuffer(body))
+	req, newErr := http.NewRequestWithContext(ctx, method, reqURI.String(), bytes.NewBuffer(body))
 	if newErr != nil {
 		err = fmt.Errorf("new request error, %s", newErr.Error())
 		return
 	}
-	// add X-ReqId if set in context
-	if ctx != nil && ctx.Value(c.GetTraceID()) != nil {
-		req.Header.Add(c.GetTraceID(), ctx.Value(c.GetTraceID()).(string))
+	// add X-ReqId if set in context, or the request id of logit.WithRequestID
+	reqID, _ := ctx.Value(c.GetTraceID()).(string)
+	if reqID == "" {
+		reqID = logit.RequestID(ctx)
+	}
+	if reqID != "" {
+		req.Header.Add(c.GetTraceID(), reqID)
 	}
 	req.Header.Add("Content-Type", "application/json; charset=utf-8")
 	// copy the extra headers
@@ -61,11 +92,18 @@ func (c *APIClient) Call(ctx context.Context, reqUrl, method string, header http
 			req.Header.Add(key, value)
 		}
 	}
+	// sign the request after all headers are set
+	if c.signer != nil {
+		if signErr := c.signer.Sign(req); signErr != nil {
+			err = fmt.Errorf("sign request error, %s", signErr.Error())
+			return
+		}
+	}
 
 	// fire the request
 	resp, callErr := c.client.Do(req)
 	if callErr != nil {
-		err = fmt.Errorf("call api failed, %s", callErr.Error())
+		err = fmt.Errorf("call api failed, %w", callErr)
 		return
 	}
 	defer func() {
@@ -83,13 +121,14 @@ func (c *APIClient) Call(ctx context.Context, reqUrl, method string, header http
 	decodeErr := jsonDecoder.Decode(apiRet)
 	if resp.StatusCode/100 != 2 {
 		// check for normal logic
-		if decodeErr != nil {
-			err = fmt.Errorf("status=%s", resp.Status)
-		} else {
-			err = fmt.Errorf("status=%s, error=%s, %s", resp.Status, apiRet.RetCode(), apiRet.RetMessage())
+		statusErr := &StatusError{StatusCode: resp.StatusCode, Status: resp.Status}
+		statusErr.RetryAfter, _ = hooks.ParseRetryAfter(resp.Header.Get("Retry-After"), time.Now())
+		if decodeErr == nil {
+			statusErr.Code, statusErr.Message, statusErr.decoded = apiRet.RetCode(), apiRet.RetMessage(), true
 		}
+		err = statusErr
 	} else if !apiRet.IsOk() {
-		err = fmt.Errorf("%s, %s", apiRet.RetCode(), apiRet.RetMessage())
+		err = &RetError{Code: apiRet.RetCode(), Message: apiRet.RetMessage()}
 	}
 	return
 }
@@ -0,0 +1,29 @@
+package rpc
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/duoland/base/logit"
+)
+
+func TestCallForwardsRequestID(t *testing.T) {
+	var got string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		got = req.Header.Get(XHeaderLogID)
+		w.Write([]byte(`{"code":"OK"}`))
+	}))
+	defer server.Close()
+
+	client := NewClientWithTimeout(time.Second)
+	ctx := logit.WithRequestID(context.Background(), "req-1")
+	if err := client.Call(ctx, server.URL, http.MethodGet, nil, nil, nil, nil); err != nil {
+		t.Fatal(err)
+	}
+	if got != "req-1" {
+		t.Fatalf("request id should be forwarded, got %q", got)
+	}
+}
@@ -0,0 +1,87 @@
+package rpc
+
+import (
+	"errors"
+	"time"
+
+	"github.com/duoland/base/hooks"
+)
+
+// SetRetryPolicy retries the failed calls by the policy, set nil to disable
+func (c *APIClient) SetRetryPolicy(policy *hooks.RetryPolicy) {
+	c.retry = policy
+}
+
+// SetCircuitBreaker enables a circuit breaker per target host, set nil to disable.
+// The failures are classified by IsServerFailure unless opts.IsFailure is set.
+func (c *APIClient) SetCircuitBreaker(opts *hooks.BreakerOptions) {
+	if opts == nil {
+		c.breakers = nil
+		return
+	}
+	breakerOpts := *opts
+	if breakerOpts.IsFailure == nil {
+		breakerOpts.IsFailure = IsServerFailure
+	}
+	c.breakers = hooks.NewBreakerGroup(breakerOpts)
+}
+
+// CircuitBreaker returns the circuit breaker of the host, nil when not enabled
+func (c *APIClient) CircuitBreaker(host string) *hooks.CircuitBreaker {
+	if c.breakers == nil {
+		return nil
+	}
+	return c.breakers.Get(host)
+}
+
+// SetBulkhead limits the concurrent calls per target host, set maxConcurrent to 0 to disable
+func (c *APIClient) SetBulkhead(maxConcurrent, maxQueue int, queueTimeout time.Duration) {
+	if maxConcurrent <= 0 {
+		c.bulkheads = nil
+		return
+	}
+	c.bulkheads = hooks.NewBulkheadGroup(maxConcurrent, maxQueue, queueTimeout)
+}
+
+// SetRateLimiter limits the call rate per target host, set nil to disable.
+// The adaptive limiters slow down on 429 and 503 and pause for the Retry-After.
+//
+//	client.SetRateLimiter(hooks.NewKeyedLimiter(func(host string) hooks.Limiter {
+//		return hooks.NewAdaptiveLimiter(hooks.AdaptiveOptions{Rate: 10, Burst: 5})
+//	}, 10*time.Minute))
+func (c *APIClient) SetRateLimiter(limiters *hooks.KeyedLimiter) {
+	c.limiters = limiters
+}
+
+// guard returns the guard of the host, nil when nothing is enabled
+func (c *APIClient) guard(host string) *hooks.Guard {
+	if c.retry == nil && c.breakers == nil && c.bulkheads == nil && c.limiters == nil {
+		return nil
+	}
+	guard := &hooks.Guard{Retry: c.retry}
+	if c.breakers != nil {
+		guard.Breaker = c.breakers.Get(host)
+	}
+	if c.bulkheads != nil {
+		guard.Bulkhead = c.bulkheads.Get(host)
+	}
+	if c.limiters != nil {
+		guard.Limiter = c.limiters.Get(host)
+	}
+	return guard
+}
+
+// IsServerFailure returns true when the error means the server is unhealthy, which are
+// the 5xx statuses and the transport errors. The other statuses and the api ret errors are
+// the results of a healthy server.
+func IsServerFailure(err error) bool {
+	if err == nil {
+		return false
+	}
+	var statusErr *StatusError
+	if errors.As(err, &statusErr) {
+		return statusErr.StatusCode >= 500
+	}
+	var retErr *RetError
+	return !errors.As(err, &retErr)
+}
@@ -0,0 +1,133 @@
+package rpc
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/duoland/base/hooks"
+)
+
+func newRetServer(status int, code string, header http.Header) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		for key, values := range header {
+			w.Header()[key] = values
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(status)
+		w.Write([]byte(`{"code":"` + code + `","msg":"test"}`))
+	}))
+}
+
+func TestCircuitBreakerPerHost(t *testing.T) {
+	failing := newRetServer(http.StatusInternalServerError, "InternalError", nil)
+	defer failing.Close()
+	healthy := newRetServer(http.StatusOK, ErrNone, nil)
+	defer healthy.Close()
+
+	client := NewClientWithTimeout(time.Second)
+	client.SetCircuitBreaker(&hooks.BreakerOptions{ConsecutiveFailures: 2, OpenTimeout: time.Minute})
+	ctx := context.Background()
+	for i := 0; i < 2; i++ {
+		var statusErr *StatusError
+		if err := client.Call(ctx, failing.URL, http.MethodGet, nil, nil, nil, nil); !errors.As(err, &statusErr) {
+			t.Fatalf("expected StatusError, got %v", err)
+		}
+	}
+	if err := client.Call(ctx, failing.URL, http.MethodGet, nil, nil, nil, nil); !errors.Is(err, hooks.ErrCircuitOpen) {
+		t.Fatalf("5xx should open the breaker, got %v", err)
+	}
+
+	if err := client.Call(ctx, healthy.URL, http.MethodGet, nil, nil, nil, nil); err != nil {
+		t.Fatalf("the breaker of another host should be closed, got %v", err)
+	}
+	healthyURL, _ := url.Parse(healthy.URL)
+	if state := client.CircuitBreaker(healthyURL.Host).State(); state != hooks.StateClosed {
+		t.Fatalf("unexpected state %v of the healthy host", state)
+	}
+}
+
+func TestCircuitBreakerIgnoresRetError(t *testing.T) {
+	notFound := newRetServer(http.StatusOK, ErrResourceNotFound, nil)
+	defer notFound.Close()
+	badRequest := newRetServer(http.StatusBadRequest, "InvalidArgument", nil)
+	defer badRequest.Close()
+
+	client := NewClientWithTimeout(time.Second)
+	client.SetCircuitBreaker(&hooks.BreakerOptions{ConsecutiveFailures: 1, OpenTimeout: time.Minute})
+	ctx := context.Background()
+	for i := 0; i < 3; i++ {
+		err := client.Call(ctx, notFound.URL, http.MethodGet, nil, nil, nil, nil)
+		var retErr *RetError
+		if !errors.As(err, &retErr) || retErr.Code != ErrResourceNotFound {
+			t.Fatalf("expected RetError, got %v", err)
+		}
+		if IsServerFailure(err) {
+			t.Fatal("RetError should not be a server failure")
+		}
+
+		err = client.Call(ctx, badRequest.URL, http.MethodGet, nil, nil, nil, nil)
+		var statusErr *StatusError
+		if !errors.As(err, &statusErr) || statusErr.Code != "InvalidArgument" {
+			t.Fatalf("expected decoded StatusError, got %v", err)
+		}
+		if IsServerFailure(err) {
+			t.Fatal("4xx should not be a server failure")
+		}
+	}
+	for _, server := range []*httptest.Server{notFound, badRequest} {
+		serverURL, _ := url.Parse(server.URL)
+		if state := client.CircuitBreaker(serverURL.Host).State(); state != hooks.StateClosed {
+			t.Fatalf("unexpected state %v of %s", state, serverURL.Host)
+		}
+	}
+}
+
+func TestStatusErrorRetryAfter(t *testing.T) {
+	server := newRetServer(http.StatusTooManyRequests, "TooManyRequests", http.Header{"Retry-After": {"2"}})
+	defer server.Close()
+
+	client := NewClientWithTimeout(time.Second)
+	err := client.Call(context.Background(), server.URL, http.MethodGet, nil, nil, nil, nil)
+	var statusErr *StatusError
+	if !errors.As(err, &statusErr) {
+		t.Fatalf("expected StatusError, got %v", err)
+	}
+	if statusErr.StatusCode != http.StatusTooManyRequests || statusErr.RetryAfter != 2*time.Second {
+		t.Fatalf("unexpected status error %+v", statusErr)
+	}
+	if retryAfter, throttled := statusErr.Throttled(); !throttled || retryAfter != 2*time.Second {
+		t.Fatalf("unexpected throttled %v %v", retryAfter, throttled)
+	}
+	if IsServerFailure(err) {
+		t.Fatal("429 should not be a server failure")
+	}
+}
+
+func TestCallCanceledByContext(t *testing.T) {
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		select {
+		case <-release:
+		case <-req.Context().Done():
+		}
+	}))
+	defer server.Close()
+	defer close(release)
+
+	client := NewClientWithTimeout(time.Minute)
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	err := client.Call(ctx, server.URL, http.MethodGet, nil, nil, nil, nil)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("call should be canceled by the deadline, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Fatalf("slow call should be cut off, took %v", elapsed)
+	}
+}
@@ -7,6 +7,8 @@ import (
 	"net/url"
 	"strings"
 	"time"
+
+	"github.com/duoland/base/net/sign"
 )
 
 type Config struct {
@@ -17,6 +19,8 @@ type Config struct {
 	CustomToken string
 	UserAgent   string
 	Timeout     int
+	SignKeyID   string
+	SignSecret  string
 }
 
 func CallAPI(cfg *Config, path, method string, query url.Values, body []byte, apiRet APIRet) (err error) {
@@ -46,6 +50,10 @@ func CallAPIWithContext(ctx context.Context, cfg *Config, path, method string, q
 	if cfg.UserAgent != "" {
 		header.Set("User-Agent", cfg.UserAgent)
 	}
+	// check request signing
+	if cfg.SignSecret != "" {
+		rpcClient.SetSigner(sign.NewSigner(cfg.SignKeyID, []byte(cfg.SignSecret)))
+	}
 	// ends
 	err = rpcClient.Call(ctx, reqURL, method, header, query, body, apiRet)
 	return
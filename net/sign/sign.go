@@ -0,0 +1,234 @@
+package sign
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/duoland/base/hash"
+)
+
+const (
+	// HeaderSignature holds the signature in the format of
+	// HMAC-SHA256 KeyId={keyID},SignedHeaders={h1;h2},Signature={hex}
+	HeaderSignature = "X-Signature"
+	// HeaderTimestamp holds the unix seconds when the request is signed
+	HeaderTimestamp = "X-Signature-Timestamp"
+	// HeaderNonce holds the random nonce to prevent replaying
+	HeaderNonce = "X-Signature-Nonce"
+	// HeaderContentSHA256 holds the hex sha256 digest of the body
+	HeaderContentSHA256 = "X-Content-SHA256"
+
+	signatureScheme = "HMAC-SHA256"
+)
+
+var (
+	ErrMissingSignature = errors.New("sign: missing signature")
+	ErrInvalidSignature = errors.New("sign: invalid signature")
+	ErrUnknownKey       = errors.New("sign: unknown key id")
+	ErrStaleTimestamp   = errors.New("sign: stale timestamp")
+	ErrReplayedNonce    = errors.New("sign: replayed nonce")
+	ErrBodyDigest       = errors.New("sign: body digest mismatch")
+	ErrBodyTooLarge     = errors.New("sign: body too large")
+)
+
+// Signer signs the outgoing http requests with hmac-sha256, it is routine-safe
+type Signer struct {
+	KeyID         string
+	Secret        []byte
+	SignedHeaders []string         // extra headers to sign, such as Host and Content-Type
+	Now           func() time.Time // default time.Now
+}
+
+// NewSigner creates a signer with the key and extra headers to sign
+func NewSigner(keyID string, secret []byte, signedHeaders ...string) *Signer {
+	return &Signer{
+		KeyID:         keyID,
+		Secret:        secret,
+		SignedHeaders: signedHeaders,
+	}
+}
+
+// Sign reads the request body to digest it, and sets the signature headers,
+// the body is restored so the request can still be sent.
+func (s *Signer) Sign(req *http.Request) error {
+	body, err := readBody(req, 0)
+	if err != nil {
+		return err
+	}
+	now := time.Now
+	if s.Now != nil {
+		now = s.Now
+	}
+	nonce, err := newNonce()
+	if err != nil {
+		return err
+	}
+	bodyDigest := sha256.Sum256(body)
+
+	req.Header.Set(HeaderTimestamp, strconv.FormatInt(now().Unix(), 10))
+	req.Header.Set(HeaderNonce, nonce)
+	req.Header.Set(HeaderContentSHA256, hex.EncodeToString(bodyDigest[:]))
+
+	signedHeaders := normalizeHeaders(s.SignedHeaders)
+	signature := hash.HmacSha256([]byte(CanonicalRequest(req, signedHeaders)), s.Secret)
+	req.Header.Set(HeaderSignature, fmt.Sprintf("%s KeyId=%s,SignedHeaders=%s,Signature=%s",
+		signatureScheme, s.KeyID, strings.Join(signedHeaders, ";"), hex.EncodeToString(signature)))
+	return nil
+}
+
+// CanonicalRequest creates the string to sign from the request, which consists of
+// the method, escaped path, sorted query, signed headers, timestamp, nonce and body digest.
+func CanonicalRequest(req *http.Request, signedHeaders []string) string {
+	var buf strings.Builder
+	buf.WriteString(req.Method)
+	buf.WriteByte('\n')
+	path := req.URL.EscapedPath()
+	if path == "" {
+		path = "/"
+	}
+	buf.WriteString(path)
+	buf.WriteByte('\n')
+	buf.WriteString(req.URL.Query().Encode())
+	buf.WriteByte('\n')
+	for _, name := range signedHeaders {
+		buf.WriteString(name)
+		buf.WriteByte(':')
+		buf.WriteString(headerValue(req, name))
+		buf.WriteByte('\n')
+	}
+	buf.WriteString(strings.Join(signedHeaders, ";"))
+	buf.WriteByte('\n')
+	buf.WriteString(req.Header.Get(HeaderTimestamp))
+	buf.WriteByte('\n')
+	buf.WriteString(req.Header.Get(HeaderNonce))
+	buf.WriteByte('\n')
+	buf.WriteString(req.Header.Get(HeaderContentSHA256))
+	return buf.String()
+}
+
+// normalizeHeaders lowercases, deduplicates and sorts the header names
+func normalizeHeaders(names []string) []string {
+	seen := make(map[string]struct{}, len(names))
+	normalized := make([]string, 0, len(names))
+	for _, name := range names {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if name == "" {
+			continue
+		}
+		if _, ok := seen[name]; ok {
+			continue
+		}
+		seen[name] = struct{}{}
+		normalized = append(normalized, name)
+	}
+	sort.Strings(normalized)
+	return normalized
+}
+
+func headerValue(req *http.Request, name string) string {
+	if name == "host" {
+		if req.Host != "" {
+			return req.Host
+		}
+		return req.URL.Host
+	}
+	values := req.Header.Values(name)
+	for i := range values {
+		values[i] = strings.TrimSpace(values[i])
+	}
+	return strings.Join(values, ",")
+}
+
+// readBody reads the body and restores it for the later readers,
+// ErrBodyTooLarge is returned when the body exceeds maxBytes, maxBytes <= 0 means no limit.
+func readBody(req *http.Request, maxBytes int64) (body []byte, err error) {
+	if req.Body == nil || req.Body == http.NoBody {
+		return
+	}
+	if maxBytes > 0 && req.ContentLength > maxBytes {
+		req.Body.Close()
+		err = ErrBodyTooLarge
+		return
+	}
+	reader := io.Reader(req.Body)
+	if maxBytes > 0 {
+		reader = io.LimitReader(req.Body, maxBytes+1)
+	}
+	body, err = io.ReadAll(reader)
+	req.Body.Close()
+	if err != nil {
+		err = fmt.Errorf("sign: read body error, %s", err.Error())
+		return
+	}
+	if maxBytes > 0 && int64(len(body)) > maxBytes {
+		body, err = nil, ErrBodyTooLarge
+		return
+	}
+	req.Body = io.NopCloser(bytes.NewReader(body))
+	req.GetBody = func() (io.ReadCloser, error) {
+		return io.NopCloser(bytes.NewReader(body)), nil
+	}
+	return
+}
+
+func newNonce() (string, error) {
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("sign: generate nonce error, %s", err.Error())
+	}
+	return hex.EncodeToString(buf), nil
+}
+
+// parseSignature parses the signature header into key id, signed headers and signature
+func parseSignature(value string) (keyID string, signedHeaders []string, signature []byte, err error) {
+	scheme, params, found := strings.Cut(value, " ")
+	if !found || scheme != signatureScheme {
+		err = ErrInvalidSignature
+		return
+	}
+	var hasSignedHeaders bool
+	for _, param := range strings.Split(params, ",") {
+		name, v, _ := strings.Cut(strings.TrimSpace(param), "=")
+		switch name {
+		case "KeyId":
+			keyID = v
+		case "SignedHeaders":
+			hasSignedHeaders = true
+			if v != "" {
+				signedHeaders = strings.Split(v, ";")
+			}
+		case "Signature":
+			if signature, err = hex.DecodeString(v); err != nil {
+				err = ErrInvalidSignature
+				return
+			}
+		}
+	}
+	if keyID == "" || !hasSignedHeaders || len(signature) == 0 {
+		err = ErrInvalidSignature
+		return
+	}
+	// the signed headers must be in canonical form, or the same signature
+	// could be presented with a different header list
+	if strings.Join(normalizeHeaders(signedHeaders), ";") != strings.Join(signedHeaders, ";") {
+		err = ErrInvalidSignature
+		return
+	}
+	return
+}
+
+// verifyMAC compares the signatures in constant time
+func verifyMAC(canonical string, secret, signature []byte) bool {
+	return hmac.Equal(hash.HmacSha256([]byte(canonical), secret), signature)
+}
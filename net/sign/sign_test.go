@@ -0,0 +1,125 @@
+package sign
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+var testSecrets = map[string][]byte{"svc-a": []byte("secret-a")}
+
+func newSignedRequest(t *testing.T, body string) *http.Request {
+	req := httptest.NewRequest(http.MethodPost, "http://example.com/hooks/order?b=2&a=1", bytes.NewBufferString(body))
+	req.Header.Set("Content-Type", "application/json")
+	signer := NewSigner("svc-a", testSecrets["svc-a"], "Content-Type", "Host")
+	if err := signer.Sign(req); err != nil {
+		t.Fatal(err)
+	}
+	return req
+}
+
+func TestSignAndVerify(t *testing.T) {
+	req := newSignedRequest(t, `{"id":1}`)
+	if err := NewVerifier(testSecrets).Verify(req); err != nil {
+		t.Fatal(err)
+	}
+	body, _ := io.ReadAll(req.Body)
+	if string(body) != `{"id":1}` {
+		t.Fatal("body should be restored after verification")
+	}
+}
+
+func TestVerifyRejects(t *testing.T) {
+	verifier := NewVerifier(testSecrets)
+
+	// replayed
+	req := newSignedRequest(t, `{"id":1}`)
+	verifier.Verify(req)
+	req.Body = io.NopCloser(bytes.NewBufferString(`{"id":1}`))
+	if err := verifier.Verify(req); !errors.Is(err, ErrReplayedNonce) {
+		t.Fatalf("expect ErrReplayedNonce, got %v", err)
+	}
+
+	// tampered body
+	req = newSignedRequest(t, `{"id":1}`)
+	req.Body = io.NopCloser(bytes.NewBufferString(`{"id":2}`))
+	if err := verifier.Verify(req); !errors.Is(err, ErrBodyDigest) {
+		t.Fatalf("expect ErrBodyDigest, got %v", err)
+	}
+
+	// tampered query and headers
+	req = newSignedRequest(t, `{"id":1}`)
+	req.URL.RawQuery = "a=1&b=3"
+	if err := verifier.Verify(req); !errors.Is(err, ErrInvalidSignature) {
+		t.Fatalf("expect ErrInvalidSignature, got %v", err)
+	}
+	req = newSignedRequest(t, `{"id":1}`)
+	req.Header.Set("Content-Type", "text/plain")
+	if err := verifier.Verify(req); !errors.Is(err, ErrInvalidSignature) {
+		t.Fatalf("expect ErrInvalidSignature, got %v", err)
+	}
+
+	// stale
+	req = newSignedRequest(t, `{"id":1}`)
+	verifier.Now = func() time.Time { return time.Now().Add(time.Hour) }
+	if err := verifier.Verify(req); !errors.Is(err, ErrStaleTimestamp) {
+		t.Fatalf("expect ErrStaleTimestamp, got %v", err)
+	}
+	verifier.Now = nil
+
+	// missing or unknown key
+	req = httptest.NewRequest(http.MethodGet, "http://example.com/", nil)
+	if err := verifier.Verify(req); !errors.Is(err, ErrMissingSignature) {
+		t.Fatalf("expect ErrMissingSignature, got %v", err)
+	}
+	NewSigner("svc-b", []byte("secret-b")).Sign(req)
+	if err := verifier.Verify(req); !errors.Is(err, ErrUnknownKey) {
+		t.Fatalf("expect ErrUnknownKey, got %v", err)
+	}
+}
+
+func TestMiddleware(t *testing.T) {
+	handler := NewVerifier(testSecrets).Middleware(http.HandlerFunc(func(resp http.ResponseWriter, req *http.Request) {
+		resp.Write([]byte("ok"))
+	}))
+
+	resp := httptest.NewRecorder()
+	handler.ServeHTTP(resp, newSignedRequest(t, `{"id":1}`))
+	if resp.Code != http.StatusOK {
+		t.Fatalf("expect 200, got %d", resp.Code)
+	}
+
+	resp = httptest.NewRecorder()
+	handler.ServeHTTP(resp, httptest.NewRequest(http.MethodGet, "http://example.com/", nil))
+	if resp.Code != http.StatusUnauthorized {
+		t.Fatalf("expect 401, got %d", resp.Code)
+	}
+}
+
+func TestVerifyMaxBodyBytes(t *testing.T) {
+	verifier := NewVerifier(testSecrets)
+	verifier.MaxBodyBytes = 8
+	if err := verifier.Verify(newSignedRequest(t, `{"id":1}`)); err != nil {
+		t.Fatal(err)
+	}
+	if err := verifier.Verify(newSignedRequest(t, `{"id":100}`)); !errors.Is(err, ErrBodyTooLarge) {
+		t.Fatalf("expect ErrBodyTooLarge, got %v", err)
+	}
+
+	// the declared length is unknown, so the body is cut by the limit
+	req := newSignedRequest(t, `{"id":100}`)
+	req.ContentLength = -1
+	if err := verifier.Verify(req); !errors.Is(err, ErrBodyTooLarge) {
+		t.Fatalf("expect ErrBodyTooLarge, got %v", err)
+	}
+
+	resp := httptest.NewRecorder()
+	verifier.Middleware(http.NotFoundHandler()).ServeHTTP(resp, newSignedRequest(t, `{"id":100}`))
+	if resp.Code != http.StatusRequestEntityTooLarge {
+		t.Fatalf("expect 413, got %d", resp.Code)
+	}
+}
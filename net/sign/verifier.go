@@ -0,0 +1,158 @@
+package sign
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+const (
+	// DefaultMaxSkew is the default allowed clock skew of the signature timestamp
+	DefaultMaxSkew = 5 * time.Minute
+	// DefaultMaxBodyBytes is the default max size of the request body buffered for the digest
+	DefaultMaxBodyBytes = 10 << 20
+)
+
+// NonceStore remembers the nonces seen within the timestamp window
+type NonceStore interface {
+	// CheckAndStore returns false when the nonce has been seen and not expired
+	CheckAndStore(nonce string, expireAt time.Time) bool
+}
+
+// MemoryNonceStore is an in-process nonce store, it is routine-safe
+type MemoryNonceStore struct {
+	mu        sync.Mutex
+	nonces    map[string]time.Time
+	lastPurge time.Time
+}
+
+// NewMemoryNonceStore creates an in-process nonce store
+func NewMemoryNonceStore() *MemoryNonceStore {
+	return &MemoryNonceStore{
+		nonces: make(map[string]time.Time),
+	}
+}
+
+// CheckAndStore implements NonceStore
+func (s *MemoryNonceStore) CheckAndStore(nonce string, expireAt time.Time) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := time.Now()
+	// purge the expired nonces at most once a second
+	if now.Sub(s.lastPurge) > time.Second {
+		for k, v := range s.nonces {
+			if now.After(v) {
+				delete(s.nonces, k)
+			}
+		}
+		s.lastPurge = now
+	}
+	if v, ok := s.nonces[nonce]; ok && now.Before(v) {
+		return false
+	}
+	s.nonces[nonce] = expireAt
+	return true
+}
+
+// Verifier checks the signature of the incoming http requests, it is routine-safe
+type Verifier struct {
+	Secrets    func(keyID string) ([]byte, bool) // looks up the secret by key id
+	MaxSkew    time.Duration                     // default DefaultMaxSkew
+	NonceStore NonceStore                        // replay check is skipped when nil
+	Now        func() time.Time                  // default time.Now
+	// MaxBodyBytes limits the body buffered for the digest, default DefaultMaxBodyBytes, negative means no limit
+	MaxBodyBytes int64
+}
+
+// NewVerifier creates a verifier with the secrets and an in-process nonce store
+func NewVerifier(secrets map[string][]byte) *Verifier {
+	return &Verifier{
+		Secrets: func(keyID string) ([]byte, bool) {
+			secret, ok := secrets[keyID]
+			return secret, ok
+		},
+		NonceStore: NewMemoryNonceStore(),
+	}
+}
+
+// Verify checks the signature, timestamp, nonce and body digest of the request,
+// the body is restored so it can still be read by the handler.
+func (v *Verifier) Verify(req *http.Request) error {
+	signatureValue := req.Header.Get(HeaderSignature)
+	if signatureValue == "" {
+		return ErrMissingSignature
+	}
+	keyID, signedHeaders, signature, err := parseSignature(signatureValue)
+	if err != nil {
+		return err
+	}
+	secret, ok := v.Secrets(keyID)
+	if !ok {
+		return ErrUnknownKey
+	}
+
+	// check the timestamp before the signature to drop stale requests early
+	now := time.Now
+	if v.Now != nil {
+		now = v.Now
+	}
+	maxSkew := v.MaxSkew
+	if maxSkew <= 0 {
+		maxSkew = DefaultMaxSkew
+	}
+	timestamp, err := strconv.ParseInt(req.Header.Get(HeaderTimestamp), 10, 64)
+	if err != nil {
+		return ErrStaleTimestamp
+	}
+	signedAt := time.Unix(timestamp, 0)
+	if skew := now().Sub(signedAt); skew > maxSkew || skew < -maxSkew {
+		return ErrStaleTimestamp
+	}
+
+	if !verifyMAC(CanonicalRequest(req, signedHeaders), secret, signature) {
+		return ErrInvalidSignature
+	}
+
+	// the digest header is signed, now check it against the real body
+	maxBodyBytes := v.MaxBodyBytes
+	if maxBodyBytes == 0 {
+		maxBodyBytes = DefaultMaxBodyBytes
+	}
+	body, err := readBody(req, maxBodyBytes)
+	if err != nil {
+		return err
+	}
+	bodyDigest := sha256.Sum256(body)
+	if hex.EncodeToString(bodyDigest[:]) != req.Header.Get(HeaderContentSHA256) {
+		return ErrBodyDigest
+	}
+
+	// remember the nonce only after the signature is verified
+	if v.NonceStore != nil {
+		nonce := req.Header.Get(HeaderNonce)
+		if nonce == "" || !v.NonceStore.CheckAndStore(keyID+":"+nonce, signedAt.Add(maxSkew)) {
+			return ErrReplayedNonce
+		}
+	}
+	return nil
+}
+
+// Middleware rejects the requests failing the verification with 401 Unauthorized,
+// or 413 Request Entity Too Large when the body exceeds MaxBodyBytes
+func (v *Verifier) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(resp http.ResponseWriter, req *http.Request) {
+		if err := v.Verify(req); err != nil {
+			status := http.StatusUnauthorized
+			if errors.Is(err, ErrBodyTooLarge) {
+				status = http.StatusRequestEntityTooLarge
+			}
+			http.Error(resp, err.Error(), status)
+			return
+		}
+		next.ServeHTTP(resp, req)
+	})
+}